@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+	"github.com/sirupsen/logrus"
+	"os"
+	"os/signal"
+	"tickets/internal/config"
+	"tickets/internal/replay"
+	"tickets/internal/repository"
+	"tickets/internal/repository/events"
+)
+
+// Rebuilds a read model from the events store, for example:
+//
+//	go run ./cmd/replay -projection ops-bookings
+func main() {
+	projectionName := flag.String("projection", "ops-bookings", "name of the projection to rebuild")
+	flag.Parse()
+
+	log.Init(logrus.InfoLevel)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	cfg, err := config.Load()
+	if err != nil {
+		panic(err)
+	}
+
+	db, err := repository.InitDB(cfg.Postgres.URL)
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	projection, ok := replay.Projections(db)[*projectionName]
+	if !ok {
+		logrus.Fatalf("unknown projection %s", *projectionName)
+	}
+
+	replayer := replay.NewReplayer(db, events.NewRepo(db))
+	if err := replayer.Rebuild(ctx, projection); err != nil {
+		logrus.WithError(err).Fatal("Could not rebuild projection")
+	}
+
+	logrus.WithField("projection", *projectionName).Info("Projection rebuilt")
+}
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"tickets/internal/entities"
+	"time"
+)
+
+func TestEvents_Append_deduplicates_by_event_id(t *testing.T) {
+	ctx := context.Background()
+	eventID := watermill.NewUUID()
+
+	event := entities.StoredEvent{
+		EventID:        eventID,
+		EventName:      "TicketBookingConfirmed",
+		Payload:        []byte(`{"header": {"id": "` + eventID + `"}, "ticket_id": "1"}`),
+		PublishedAt:    time.Now().UTC().Truncate(time.Microsecond),
+		IdempotencyKey: watermill.NewUUID(),
+		CorrelationID:  watermill.NewUUID(),
+	}
+
+	for i := 0; i < 2; i++ {
+		err := eventsRepo.AppendEvent(ctx, event)
+		require.NoError(t, err)
+	}
+
+	var count int
+	err := getDB().GetContext(ctx, &count, "SELECT COUNT(*) FROM events WHERE event_id = $1", eventID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	got, err := eventsRepo.GetByID(ctx, eventID)
+	require.NoError(t, err)
+	assert.Equal(t, event.EventName, got.EventName)
+	assert.Equal(t, event.IdempotencyKey, got.IdempotencyKey)
+	assert.Equal(t, event.CorrelationID, got.CorrelationID)
+	assert.JSONEq(t, string(event.Payload), string(got.Payload))
+}
+
+func TestEvents_are_append_only(t *testing.T) {
+	ctx := context.Background()
+	eventID := watermill.NewUUID()
+
+	err := eventsRepo.AppendEvent(ctx, entities.StoredEvent{
+		EventID:     eventID,
+		EventName:   "BookingMade",
+		Payload:     []byte(`{}`),
+		PublishedAt: time.Now().UTC(),
+	})
+	require.NoError(t, err)
+
+	_, err = getDB().ExecContext(ctx, "DELETE FROM events WHERE event_id = $1", eventID)
+	assert.Error(t, err)
+
+	_, err = getDB().ExecContext(ctx, "UPDATE events SET event_name = 'changed' WHERE event_id = $1", eventID)
+	assert.Error(t, err)
+}
+
+func TestEvents_StoredSince(t *testing.T) {
+	ctx := context.Background()
+	eventID := watermill.NewUUID()
+
+	err := eventsRepo.AppendEvent(ctx, entities.StoredEvent{
+		EventID:     eventID,
+		EventName:   "BookingMade",
+		Payload:     []byte(`{}`),
+		PublishedAt: time.Now().UTC(),
+	})
+	require.NoError(t, err)
+
+	stored, err := eventsRepo.GetByID(ctx, eventID)
+	require.NoError(t, err)
+
+	storedSince := func(since time.Time) []string {
+		var ids []string
+		err := eventsRepo.EventsStoredSince(ctx, since, func(event entities.StoredEvent) error {
+			ids = append(ids, event.EventID)
+			return nil
+		})
+		require.NoError(t, err)
+		return ids
+	}
+
+	assert.Contains(t, storedSince(stored.StoredAt), eventID)
+	assert.NotContains(t, storedSince(stored.StoredAt.Add(time.Microsecond)), eventID)
+}
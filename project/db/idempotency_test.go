@@ -0,0 +1,85 @@
+package db
+
+import (
+	"context"
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestIdempotency_stores_response(t *testing.T) {
+	ctx := context.Background()
+	endpoint := "POST /book-tickets"
+	key := watermill.NewUUID()
+
+	_, reserved, err := idempotencyRepo.ReserveKey(ctx, endpoint, key, "hash")
+	require.NoError(t, err)
+	assert.True(t, reserved)
+
+	stored, reserved, err := idempotencyRepo.ReserveKey(ctx, endpoint, key, "hash")
+	require.NoError(t, err)
+	assert.False(t, reserved)
+	assert.True(t, stored.InProgress())
+
+	body := []byte(`{"booking_id":"1"}`)
+	require.NoError(t, idempotencyRepo.SaveResponse(ctx, endpoint, key, http.StatusCreated, body))
+
+	stored, reserved, err = idempotencyRepo.ReserveKey(ctx, endpoint, key, "other-hash")
+	require.NoError(t, err)
+	assert.False(t, reserved)
+	assert.Equal(t, "hash", stored.RequestHash)
+	assert.Equal(t, http.StatusCreated, stored.StatusCode)
+	assert.Equal(t, body, stored.Body)
+
+	// a stored response is never released
+	require.NoError(t, idempotencyRepo.ReleaseKey(ctx, endpoint, key))
+	_, reserved, err = idempotencyRepo.ReserveKey(ctx, endpoint, key, "hash")
+	require.NoError(t, err)
+	assert.False(t, reserved)
+}
+
+func TestIdempotency_release_failed_request(t *testing.T) {
+	ctx := context.Background()
+	endpoint := "POST /shows"
+	key := watermill.NewUUID()
+
+	_, reserved, err := idempotencyRepo.ReserveKey(ctx, endpoint, key, "hash")
+	require.NoError(t, err)
+	require.True(t, reserved)
+
+	require.NoError(t, idempotencyRepo.ReleaseKey(ctx, endpoint, key))
+
+	_, reserved, err = idempotencyRepo.ReserveKey(ctx, endpoint, key, "hash")
+	require.NoError(t, err)
+	assert.True(t, reserved)
+}
+
+func TestIdempotency_in_progress_key_is_not_taken_over(t *testing.T) {
+	ctx := context.Background()
+	endpoint := "POST /book-tickets"
+	key := watermill.NewUUID()
+
+	_, reserved, err := idempotencyRepo.ReserveKey(ctx, endpoint, key, "hash")
+	require.NoError(t, err)
+	require.True(t, reserved)
+
+	_, err = getDB().ExecContext(ctx, "UPDATE http_idempotency_keys SET created_at = now() - interval '1 hour' WHERE idempotency_key = $1", key)
+	require.NoError(t, err)
+
+	stored, reserved, err := idempotencyRepo.ReserveKey(ctx, endpoint, key, "hash")
+	require.NoError(t, err)
+	assert.False(t, reserved)
+	assert.True(t, stored.InProgress())
+
+	deleted, err := idempotencyRepo.DeleteKeysBefore(ctx, time.Now().Add(-30*time.Minute))
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, deleted, int64(1))
+
+	// expired keys can be used again
+	_, reserved, err = idempotencyRepo.ReserveKey(ctx, endpoint, key, "hash")
+	require.NoError(t, err)
+	assert.True(t, reserved)
+}
@@ -3,15 +3,33 @@ package db
 import (
 	"os"
 	"testing"
+	"tickets/internal/repository/booking"
+	"tickets/internal/repository/events"
+	"tickets/internal/repository/idempotency"
+	"tickets/internal/repository/processed"
+	"tickets/internal/repository/refund"
+	"tickets/internal/repository/show"
 	"tickets/internal/repository/ticket"
 )
 
 var ticketRepo *ticket.Repo
+var eventsRepo *events.Repo
+var showRepo *show.Repo
+var bookingRepo *booking.Repo
+var idempotencyRepo *idempotency.Repo
+var processedRepo *processed.Repo
+var refundRepo *refund.Repo
 
 func TestMain(m *testing.M) {
 	db := getDB()
 
 	ticketRepo = ticket.NewRepo(db)
+	eventsRepo = events.NewRepo(db)
+	showRepo = show.NewRepo(db)
+	bookingRepo = booking.NewRepo(db)
+	idempotencyRepo = idempotency.NewRepo(db)
+	processedRepo = processed.NewRepo(db)
+	refundRepo = refund.NewRepo(db)
 
 	os.Exit(m.Run())
 }
@@ -0,0 +1,71 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"tickets/internal/entities"
+	"tickets/internal/repository/readModel"
+	"time"
+)
+
+func TestOpsBookings_list_pagination(t *testing.T) {
+	ctx := context.Background()
+	db := getDB()
+
+	table := "ops_bookings_test_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	_, err := db.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s (LIKE %s INCLUDING ALL)", table, readModel.OpsBookingsTable))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = db.ExecContext(ctx, "DROP TABLE IF EXISTS "+table)
+	})
+
+	opsBookings := readModel.NewOpsBookingReadModel(db).WithTable(table)
+
+	bookedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	// two bookings share booked_at, the booking_id keeps their order stable across pages
+	offsets := []time.Duration{0, time.Hour, time.Hour, 2 * time.Hour, 3 * time.Hour}
+
+	var expected []string
+	for _, offset := range offsets {
+		header := entities.NewEventHeader("")
+		header.PublishedAt = bookedAt.Add(offset)
+
+		bookingID := uuid.NewString()
+		require.NoError(t, opsBookings.OnBookingMade(ctx, &entities.BookingMade{Header: header, BookingID: uuid.MustParse(bookingID)}))
+		expected = append(expected, bookingID)
+	}
+	// newest first, ties by booking_id descending
+	expected = []string{expected[4], expected[3], max(expected[1], expected[2]), min(expected[1], expected[2]), expected[0]}
+
+	filter := entities.OpsBookingFilter{Limit: 2}
+
+	var (
+		got   []string
+		pages int
+	)
+	for {
+		page, err := opsBookings.ReservationList(ctx, filter)
+		require.NoError(t, err)
+		pages++
+
+		for _, booking := range page.Items {
+			got = append(got, booking.BookingID.String())
+		}
+
+		if page.NextCursor == "" {
+			break
+		}
+		filter.Cursor = page.NextCursor
+	}
+
+	assert.Equal(t, expected, got)
+	assert.Equal(t, 3, pages)
+
+	_, err = opsBookings.ReservationList(ctx, entities.OpsBookingFilter{Cursor: "not-a-cursor"})
+	assert.ErrorIs(t, err, readModel.ErrInvalidCursor)
+}
@@ -0,0 +1,43 @@
+package db
+
+import (
+	"context"
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestProcessedEvents(t *testing.T) {
+	ctx := context.Background()
+	eventID := watermill.NewUUID()
+
+	processed, err := processedRepo.IsProcessed(ctx, "svc-tickets.TicketToPrint", eventID)
+	require.NoError(t, err)
+	assert.False(t, processed)
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, processedRepo.MarkProcessed(ctx, "svc-tickets.TicketToPrint", eventID))
+	}
+
+	processed, err = processedRepo.IsProcessed(ctx, "svc-tickets.TicketToPrint", eventID)
+	require.NoError(t, err)
+	assert.True(t, processed)
+
+	// other handlers and consumer groups still have to process the event
+	processed, err = processedRepo.IsProcessed(ctx, "svc-tickets.IssueReceipt", eventID)
+	require.NoError(t, err)
+	assert.False(t, processed)
+
+	processed, err = processedRepo.IsProcessed(ctx, "other-prefix.TicketToPrint", eventID)
+	require.NoError(t, err)
+	assert.False(t, processed)
+
+	_, err = processedRepo.DeleteProcessedBefore(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+
+	processed, err = processedRepo.IsProcessed(ctx, "svc-tickets.TicketToPrint", eventID)
+	require.NoError(t, err)
+	assert.False(t, processed)
+}
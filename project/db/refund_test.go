@@ -0,0 +1,141 @@
+package db
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"tickets/internal/entities"
+	"tickets/internal/repository/refund"
+	"time"
+)
+
+func TestRefund_steps(t *testing.T) {
+	ctx := context.Background()
+	ticketID := uuid.NewString()
+	idempotencyKey := uuid.NewString()
+
+	r, err := refundRepo.StartRefund(ctx, ticketID, idempotencyKey)
+	require.NoError(t, err)
+	assert.Equal(t, entities.RefundStatusStarted, r.Status)
+
+	// a redelivered command continues the refund started before
+	r, err = refundRepo.StartRefund(ctx, ticketID, uuid.NewString())
+	require.NoError(t, err)
+	assert.Equal(t, idempotencyKey, r.IdempotencyKey)
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, refundRepo.MarkReceiptVoided(ctx, ticketID))
+	}
+
+	r, err = refundRepo.RefundByTicketID(ctx, ticketID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.RefundStatusReceiptVoided, r.Status)
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, refundRepo.CompleteRefund(ctx, ticketID))
+	}
+
+	r, err = refundRepo.RefundByTicketID(ctx, ticketID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.RefundStatusCompleted, r.Status)
+
+	// voiding after the refund completed doesn't move it back
+	require.NoError(t, refundRepo.MarkReceiptVoided(ctx, ticketID))
+	r, err = refundRepo.RefundByTicketID(ctx, ticketID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.RefundStatusCompleted, r.Status)
+
+	assert.Equal(t, 1, outboxEventsOfTicket(t, "events.TicketRefunded", ticketID))
+	assert.Equal(t, 0, outboxEventsOfTicket(t, "events.TicketRefundFailed", ticketID))
+}
+
+func TestRefund_failed_halfway_and_retried(t *testing.T) {
+	ctx := context.Background()
+	ticketID := uuid.NewString()
+	const maxAttempts = 3
+
+	_, err := refundRepo.StartRefund(ctx, ticketID, uuid.NewString())
+	require.NoError(t, err)
+	require.NoError(t, refundRepo.MarkReceiptVoided(ctx, ticketID))
+
+	_, err = refundRepo.ResetFailedRefund(ctx, ticketID)
+	assert.ErrorIs(t, err, refund.ErrRefundNotFailed)
+
+	for attempt := 1; attempt < maxAttempts; attempt++ {
+		r, err := refundRepo.RecordFailure(ctx, ticketID, "payment failed", maxAttempts, time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, attempt, r.Attempts)
+		assert.Nil(t, r.FailedAt, "refund should be retried after %d failures", attempt)
+		assert.NotNil(t, r.NextAttemptAt)
+	}
+	assert.Equal(t, 0, outboxEventsOfTicket(t, "events.TicketRefundFailed", ticketID))
+
+	r, err := refundRepo.RecordFailure(ctx, ticketID, "payment failed", maxAttempts, time.Hour)
+	require.NoError(t, err)
+	assert.NotNil(t, r.FailedAt)
+	assert.Nil(t, r.NextAttemptAt, "failed refund shouldn't be retried automatically")
+	assert.Equal(t, entities.RefundStatusReceiptVoided, r.Status)
+	assert.Equal(t, "payment failed", r.LastError)
+	assert.Equal(t, 1, outboxEventsOfTicket(t, "events.TicketRefundFailed", ticketID))
+
+	failed, err := refundRepo.RefundList(ctx, entities.RefundStatusReceiptVoided, true)
+	require.NoError(t, err)
+	assert.Contains(t, refundTicketIDs(failed), ticketID)
+
+	r, err = refundRepo.ResetFailedRefund(ctx, ticketID)
+	require.NoError(t, err)
+	assert.Nil(t, r.FailedAt)
+	assert.Equal(t, 0, r.Attempts)
+	assert.Equal(t, entities.RefundStatusReceiptVoided, r.Status, "retry should continue from the step that failed")
+
+	require.NoError(t, refundRepo.CompleteRefund(ctx, ticketID))
+	assert.Equal(t, 1, outboxEventsOfTicket(t, "events.TicketRefunded", ticketID))
+}
+
+func TestRefund_retry_after_delay(t *testing.T) {
+	ctx := context.Background()
+	ticketID := uuid.NewString()
+
+	_, err := refundRepo.StartRefund(ctx, ticketID, uuid.NewString())
+	require.NoError(t, err)
+
+	_, err = refundRepo.RecordFailure(ctx, ticketID, "receipts unavailable", 5, time.Hour)
+	require.NoError(t, err)
+	assert.NotContains(t, refundsToRetry(t), ticketID, "retry shouldn't be due before the delay")
+
+	_, err = refundRepo.RecordFailure(ctx, ticketID, "receipts unavailable", 5, time.Microsecond)
+	require.NoError(t, err)
+	assert.Contains(t, refundsToRetry(t), ticketID)
+
+	require.NoError(t, refundRepo.ClearRetry(ctx, ticketID))
+	assert.NotContains(t, refundsToRetry(t), ticketID)
+
+	r, err := refundRepo.RefundByTicketID(ctx, ticketID)
+	require.NoError(t, err)
+	assert.Nil(t, r.NextAttemptAt)
+	assert.Equal(t, 2, r.Attempts)
+}
+
+func TestRefund_ResetFailedRefund_not_found(t *testing.T) {
+	_, err := refundRepo.ResetFailedRefund(context.Background(), uuid.NewString())
+	assert.ErrorIs(t, err, refund.ErrRefundNotFound)
+}
+
+func refundsToRetry(t *testing.T) []string {
+	t.Helper()
+
+	refunds, err := refundRepo.RefundsToRetry(context.Background())
+	require.NoError(t, err)
+
+	return refundTicketIDs(refunds)
+}
+
+func refundTicketIDs(refunds []entities.Refund) []string {
+	ids := make([]string, 0, len(refunds))
+	for _, r := range refunds {
+		ids = append(ids, r.TicketID)
+	}
+	return ids
+}
@@ -0,0 +1,160 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ThreeDotsLabs/watermill/components/cqrs"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sort"
+	"strings"
+	"testing"
+	"tickets/internal/entities"
+	"tickets/internal/replay"
+	"time"
+)
+
+func TestReplay_Rebuild_swaps_rebuilt_shadow_table(t *testing.T) {
+	ctx := context.Background()
+	db := getDB()
+
+	table := "replay_test_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	_, err := db.ExecContext(ctx, fmt.Sprintf(
+		"CREATE TABLE %s (booking_id UUID PRIMARY KEY, number_of_tickets INT NOT NULL, canceled BOOLEAN NOT NULL DEFAULT false)",
+		table,
+	))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = db.ExecContext(ctx, "DROP TABLE IF EXISTS "+table)
+	})
+
+	staleBookingID := uuid.New()
+	_, err = db.ExecContext(ctx, "INSERT INTO "+table+" (booking_id, number_of_tickets) VALUES ($1, 1)", staleBookingID)
+	require.NoError(t, err)
+
+	var now time.Time
+	require.NoError(t, db.GetContext(ctx, &now, "SELECT localtimestamp"))
+	longAgo := now.Add(-24 * time.Hour)
+
+	made, canceled, committedLate, caughtUp := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	store := &eventsStoreStub{
+		history: []entities.StoredEvent{
+			// stored before the booking it cancels, it's projected once the booking is there
+			storedEvent(t, 1, longAgo, entities.BookingCanceled{Header: entities.NewEventHeader(""), BookingID: canceled}),
+			storedEvent(t, 2, longAgo, entities.BookingMade{Header: entities.NewEventHeader(""), BookingID: made, NumberOfTickets: 2}),
+			// stored just before the replay, the catch-up returns it again
+			storedEvent(t, 4, now, entities.BookingMade{Header: entities.NewEventHeader(""), BookingID: canceled, NumberOfTickets: 3}),
+		},
+		// committed while the history was replayed
+		storedLater: []entities.StoredEvent{
+			// got its seq before the last replayed event, but committed after it
+			storedEvent(t, 3, now, entities.BookingMade{Header: entities.NewEventHeader(""), BookingID: committedLate, NumberOfTickets: 4}),
+			storedEvent(t, 5, now, entities.BookingMade{Header: entities.NewEventHeader(""), BookingID: caughtUp, NumberOfTickets: 5}),
+		},
+	}
+
+	err = replay.NewReplayer(db, store).Rebuild(ctx, bookingsProjection{db: db, table: table})
+	require.NoError(t, err)
+
+	var rows []struct {
+		BookingID       uuid.UUID `db:"booking_id"`
+		NumberOfTickets int       `db:"number_of_tickets"`
+		Canceled        bool      `db:"canceled"`
+	}
+	require.NoError(t, db.SelectContext(ctx, &rows, "SELECT booking_id, number_of_tickets, canceled FROM "+table+" ORDER BY number_of_tickets"))
+
+	require.Len(t, rows, 4)
+	assert.Equal(t, made, rows[0].BookingID)
+	assert.False(t, rows[0].Canceled)
+	assert.Equal(t, canceled, rows[1].BookingID)
+	assert.True(t, rows[1].Canceled)
+	assert.Equal(t, committedLate, rows[2].BookingID)
+	assert.Equal(t, caughtUp, rows[3].BookingID)
+
+	for _, leftover := range []string{table + "_rebuild", table + "_old"} {
+		var exists bool
+		require.NoError(t, db.GetContext(ctx, &exists, "SELECT to_regclass($1) IS NOT NULL", leftover))
+		assert.False(t, exists, "table %s should be dropped", leftover)
+	}
+}
+
+type eventsStoreStub struct {
+	history     []entities.StoredEvent
+	storedLater []entities.StoredEvent
+}
+
+// EventsAfter returns the history, the events stored later are committed only after it's read.
+func (s *eventsStoreStub) EventsAfter(ctx context.Context, afterSeq int64, fn func(event entities.StoredEvent) error) (int64, error) {
+	lastSeq := afterSeq
+	for _, event := range s.history {
+		if event.Seq <= afterSeq {
+			continue
+		}
+		if err := fn(event); err != nil {
+			return 0, err
+		}
+		lastSeq = event.Seq
+	}
+
+	return lastSeq, nil
+}
+
+func (s *eventsStoreStub) EventsStoredSince(ctx context.Context, since time.Time, fn func(event entities.StoredEvent) error) error {
+	events := append(append([]entities.StoredEvent{}, s.history...), s.storedLater...)
+	sort.Slice(events, func(i, j int) bool { return events[i].Seq < events[j].Seq })
+
+	for _, event := range events {
+		if event.StoredAt.Before(since) {
+			continue
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func storedEvent(t *testing.T, seq int64, storedAt time.Time, event any) entities.StoredEvent {
+	payload, err := json.Marshal(event)
+	require.NoError(t, err)
+
+	return entities.StoredEvent{
+		Seq:       seq,
+		EventID:   uuid.NewString(),
+		EventName: cqrs.StructName(event),
+		Payload:   payload,
+		StoredAt:  storedAt,
+	}
+}
+
+type bookingsProjection struct {
+	db    *sqlx.DB
+	table string
+}
+
+func (p bookingsProjection) Table() string {
+	return p.table
+}
+
+func (p bookingsProjection) EventHandlers(table string) []cqrs.EventHandler {
+	return []cqrs.EventHandler{
+		cqrs.NewEventHandler("OnBookingMade", func(ctx context.Context, event *entities.BookingMade) error {
+			_, err := p.db.ExecContext(ctx, "INSERT INTO "+table+" (booking_id, number_of_tickets) VALUES ($1, $2)", event.BookingID, event.NumberOfTickets)
+			return err
+		}),
+		cqrs.NewEventHandler("OnBookingCanceled", func(ctx context.Context, event *entities.BookingCanceled) error {
+			res, err := p.db.ExecContext(ctx, "UPDATE "+table+" SET canceled = true WHERE booking_id = $1", event.BookingID)
+			if err != nil {
+				return err
+			}
+			if updated, _ := res.RowsAffected(); updated == 0 {
+				return fmt.Errorf("booking %s not found", event.BookingID)
+			}
+			return nil
+		}),
+	}
+}
@@ -0,0 +1,154 @@
+package db
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"tickets/internal/entities"
+	"tickets/internal/repository/readModel"
+	"time"
+)
+
+func TestShowAvailability_redelivered_events_are_counted_once(t *testing.T) {
+	ctx := context.Background()
+	showAvailability := readModel.NewShowAvailabilityReadModel(getDB())
+
+	showID, err := showRepo.NewShow(ctx, entities.Show{
+		ShowID:         uuid.NewString(),
+		DeadNationID:   uuid.New(),
+		NumberOfTicket: 10,
+		StartTime:      time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second),
+		Title:          "test show",
+		Venue:          "test venue",
+	})
+	require.NoError(t, err)
+
+	booking := entities.Booking{
+		BookingID:       uuid.New(),
+		ShowID:          uuid.MustParse(showID),
+		NumberOfTickets: 4,
+		CustomerEmail:   "test",
+	}
+	_, err = bookingRepo.BookTicket(ctx, booking)
+	require.NoError(t, err)
+
+	assertAvailableSeats := func(expected int) {
+		t.Helper()
+
+		availability, err := showAvailability.ShowAvailability(ctx, booking.ShowID)
+		require.NoError(t, err)
+		assert.Equal(t, expected, availability.AvailableSeats)
+	}
+
+	made := &entities.BookingMade{
+		Header:          entities.NewEventHeader(booking.BookingID.String()),
+		NumberOfTickets: booking.NumberOfTickets,
+		BookingID:       booking.BookingID,
+		CustomerEmail:   booking.CustomerEmail,
+		ShowId:          booking.ShowID,
+	}
+	for i := 0; i < 2; i++ {
+		require.NoError(t, showAvailability.OnBookingMade(ctx, made))
+	}
+	assertAvailableSeats(6)
+
+	ticketCanceled := &entities.TicketBookingCanceled{
+		Header:    entities.NewEventHeader(uuid.NewString()),
+		TicketID:  uuid.NewString(),
+		BookingID: booking.BookingID.String(),
+	}
+	for i := 0; i < 2; i++ {
+		require.NoError(t, showAvailability.OnTicketBookingCanceled(ctx, ticketCanceled))
+	}
+	assertAvailableSeats(7)
+
+	require.NoError(t, bookingRepo.CancelBooking(ctx, booking.BookingID))
+
+	bookingCanceled := &entities.BookingCanceled{
+		Header:          entities.NewEventHeader(booking.BookingID.String()),
+		BookingID:       booking.BookingID,
+		ShowID:          booking.ShowID,
+		NumberOfTickets: booking.NumberOfTickets,
+		CustomerEmail:   booking.CustomerEmail,
+	}
+	for i := 0; i < 2; i++ {
+		require.NoError(t, showAvailability.OnBookingCanceled(ctx, bookingCanceled))
+	}
+	assertAvailableSeats(10)
+
+	// redelivered after the whole booking was canceled
+	require.NoError(t, showAvailability.OnTicketBookingCanceled(ctx, ticketCanceled))
+	assertAvailableSeats(10)
+}
+
+func TestShowAvailability_concurrent_ticket_and_booking_cancel_release_seats_once(t *testing.T) {
+	ctx := context.Background()
+	showAvailability := readModel.NewShowAvailabilityReadModel(getDB())
+
+	const bookings = 20
+
+	showID, err := showRepo.NewShow(ctx, entities.Show{
+		ShowID:         uuid.NewString(),
+		DeadNationID:   uuid.New(),
+		NumberOfTicket: bookings * 2,
+		StartTime:      time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second),
+		Title:          "test show",
+		Venue:          "test venue",
+	})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < bookings; i++ {
+		booking := entities.Booking{
+			BookingID:       uuid.New(),
+			ShowID:          uuid.MustParse(showID),
+			NumberOfTickets: 2,
+			CustomerEmail:   "test",
+		}
+		_, err := bookingRepo.BookTicket(ctx, booking)
+		require.NoError(t, err)
+
+		require.NoError(t, showAvailability.OnBookingMade(ctx, &entities.BookingMade{
+			Header:          entities.NewEventHeader(booking.BookingID.String()),
+			NumberOfTickets: booking.NumberOfTickets,
+			BookingID:       booking.BookingID,
+			CustomerEmail:   booking.CustomerEmail,
+			ShowId:          booking.ShowID,
+		}))
+
+		// a ticket of the booking and the whole booking are canceled at the same time
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+
+			assert.NoError(t, showAvailability.OnTicketBookingCanceled(ctx, &entities.TicketBookingCanceled{
+				Header:    entities.NewEventHeader(uuid.NewString()),
+				TicketID:  uuid.NewString(),
+				BookingID: booking.BookingID.String(),
+			}))
+		}()
+		go func() {
+			defer wg.Done()
+
+			if !assert.NoError(t, bookingRepo.CancelBooking(ctx, booking.BookingID)) {
+				return
+			}
+			assert.NoError(t, showAvailability.OnBookingCanceled(ctx, &entities.BookingCanceled{
+				Header:          entities.NewEventHeader(booking.BookingID.String()),
+				BookingID:       booking.BookingID,
+				ShowID:          booking.ShowID,
+				NumberOfTickets: booking.NumberOfTickets,
+				CustomerEmail:   booking.CustomerEmail,
+			}))
+		}()
+	}
+	wg.Wait()
+
+	availability, err := showAvailability.ShowAvailability(ctx, uuid.MustParse(showID))
+	require.NoError(t, err)
+	assert.Equal(t, 0, availability.BookedSeats)
+	assert.Equal(t, bookings*2, availability.AvailableSeats)
+}
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"tickets/internal/entities"
+	"tickets/internal/repository/booking"
+	"tickets/internal/repository/show"
+	"time"
+)
+
+func TestShow_update_and_cancel(t *testing.T) {
+	ctx := context.Background()
+
+	showID, err := showRepo.NewShow(ctx, entities.Show{
+		ShowID:         uuid.NewString(),
+		DeadNationID:   uuid.New(),
+		NumberOfTicket: 10,
+		StartTime:      time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second),
+		Title:          "test show",
+		Venue:          "test venue",
+	})
+	require.NoError(t, err)
+
+	_, err = bookingRepo.BookTicket(ctx, entities.Booking{
+		BookingID:       uuid.New(),
+		ShowID:          uuid.MustParse(showID),
+		NumberOfTickets: 4,
+		CustomerEmail:   "test",
+	})
+	require.NoError(t, err)
+
+	tooFew := 3
+	_, err = showRepo.UpdateShow(ctx, uuid.MustParse(showID), entities.ShowUpdate{NumberOfTickets: &tooFew})
+	assert.ErrorAs(t, err, &show.SeatsBelowBookedError{})
+
+	venue := "new venue"
+	updated, err := showRepo.UpdateShow(ctx, uuid.MustParse(showID), entities.ShowUpdate{Venue: &venue})
+	require.NoError(t, err)
+	assert.Equal(t, venue, updated.Venue)
+	assert.Equal(t, "test show", updated.Title)
+	assert.Equal(t, 10, updated.NumberOfTicket)
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, showRepo.CancelShow(ctx, uuid.MustParse(showID)))
+	}
+
+	got, err := showRepo.ShowByID(ctx, uuid.MustParse(showID))
+	require.NoError(t, err)
+	assert.NotNil(t, got.CanceledAt)
+
+	_, err = showRepo.UpdateShow(ctx, uuid.MustParse(showID), entities.ShowUpdate{Venue: &venue})
+	assert.ErrorIs(t, err, show.ErrShowCanceled)
+
+	_, err = bookingRepo.BookTicket(ctx, entities.Booking{
+		BookingID:       uuid.New(),
+		ShowID:          uuid.MustParse(showID),
+		NumberOfTickets: 1,
+		CustomerEmail:   "test",
+	})
+	assert.ErrorIs(t, err, booking.ErrShowCanceled)
+}
+
+func TestShow_not_found(t *testing.T) {
+	_, err := showRepo.ShowByID(context.Background(), uuid.New())
+	assert.ErrorIs(t, err, show.ErrShowNotFound)
+}
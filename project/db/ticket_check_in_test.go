@@ -0,0 +1,47 @@
+package db
+
+import (
+	"context"
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"tickets/internal/entities"
+	"tickets/internal/repository/ticket"
+	"time"
+)
+
+func TestTicket_check_in(t *testing.T) {
+	ctx := context.Background()
+
+	confirmed := testTicketBookingConfirmed(watermill.NewUUID())
+	require.NoError(t, ticketRepo.SaveTicket(ctx, confirmed))
+
+	checkIn, err := ticketRepo.CheckInTicket(ctx, confirmed.TicketID, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, confirmed.TicketID, checkIn.TicketID)
+	assert.False(t, checkIn.CheckedInAt.IsZero())
+
+	_, err = ticketRepo.CheckInTicket(ctx, confirmed.TicketID, time.Now())
+	assert.ErrorIs(t, err, ticket.ErrTicketAlreadyCheckedIn)
+
+	canceled := testTicketBookingConfirmed(watermill.NewUUID())
+	require.NoError(t, ticketRepo.CancelTicket(ctx, entities.TicketBookingCanceled{
+		TicketID:      canceled.TicketID,
+		CustomerEmail: canceled.CustomerEmail,
+		Price:         canceled.Price,
+	}))
+
+	_, err = ticketRepo.CheckInTicket(ctx, canceled.TicketID, time.Now())
+	assert.ErrorIs(t, err, ticket.ErrTicketNotConfirmed)
+
+	refunded := testTicketBookingConfirmed(watermill.NewUUID())
+	require.NoError(t, ticketRepo.SaveTicket(ctx, refunded))
+	require.NoError(t, ticketRepo.RefundTicket(ctx, refunded.TicketID, time.Now()))
+
+	_, err = ticketRepo.CheckInTicket(ctx, refunded.TicketID, time.Now())
+	assert.ErrorIs(t, err, ticket.ErrTicketNotConfirmed)
+
+	_, err = ticketRepo.CheckInTicket(ctx, watermill.NewUUID(), time.Now())
+	assert.ErrorIs(t, err, ticket.ErrTicketNotFound)
+}
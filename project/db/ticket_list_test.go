@@ -0,0 +1,52 @@
+package db
+
+import (
+	"context"
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"tickets/internal/entities"
+)
+
+func TestTicket_list_pagination(t *testing.T) {
+	ctx := context.Background()
+	bookingID := watermill.NewUUID()
+
+	prices := []string{"10.00", "30.00", "20.00", "20.00", "50.00"}
+	for _, price := range prices {
+		ticket := testTicketBookingConfirmed(watermill.NewUUID())
+		ticket.BookingID = bookingID
+		ticket.Price.Amount = price
+		require.NoError(t, ticketRepo.SaveTicket(ctx, ticket))
+	}
+
+	filter := entities.TicketFilter{
+		BookingID: bookingID,
+		MinPrice:  "15",
+		Sort:      "-price",
+		Limit:     2,
+	}
+
+	var (
+		got   []string
+		pages int
+	)
+	for {
+		page, err := ticketRepo.TicketList(ctx, filter)
+		require.NoError(t, err)
+		pages++
+
+		for _, ticket := range page.Items {
+			got = append(got, ticket.Price.Amount)
+		}
+
+		if page.NextCursor == "" {
+			break
+		}
+		filter.Cursor = page.NextCursor
+	}
+
+	assert.Equal(t, []string{"50.00", "30.00", "20.00", "20.00"}, got)
+	assert.Equal(t, 2, pages)
+}
@@ -0,0 +1,63 @@
+package db
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"tickets/internal/entities"
+)
+
+func TestTicket_record_status_changes_publishes_events_once(t *testing.T) {
+	ctx := context.Background()
+	idempotencyKey := uuid.NewString()
+
+	confirmedID := uuid.NewString()
+	canceledID := uuid.NewString()
+	tickets := []entities.Ticket{
+		{
+			TicketID:      confirmedID,
+			Status:        entities.TicketStatusConfirmed,
+			CustomerEmail: "test@example.com",
+			Price:         entities.Money{Amount: "10", Currency: "EUR"},
+		},
+		{
+			TicketID:      canceledID,
+			Status:        entities.TicketStatusCanceled,
+			CustomerEmail: "test@example.com",
+			Price:         entities.Money{Amount: "10", Currency: "EUR"},
+		},
+	}
+
+	// a retried request with the same idempotency key
+	for i := 0; i < 2; i++ {
+		require.NoError(t, ticketRepo.RecordStatusChanges(ctx, idempotencyKey, tickets))
+	}
+
+	var recorded int
+	err := getDB().GetContext(ctx, &recorded, `SELECT COUNT(*) FROM ticket_status_changes WHERE idempotency_key = $1`, idempotencyKey)
+	require.NoError(t, err)
+	assert.Equal(t, 2, recorded)
+
+	assert.Equal(t, 1, outboxEventsOfTicket(t, "events.TicketBookingConfirmed", confirmedID))
+	// TicketPrinted is published once the ticket file is stored
+	assert.Equal(t, 0, outboxEventsOfTicket(t, "events.TicketPrinted", confirmedID))
+	assert.Equal(t, 1, outboxEventsOfTicket(t, "events.TicketBookingCanceled", canceledID))
+	assert.Equal(t, 0, outboxEventsOfTicket(t, "events.TicketBookingConfirmed", canceledID))
+}
+
+func outboxEventsOfTicket(t *testing.T, topic, ticketID string) int {
+	t.Helper()
+
+	var count int
+	err := getDB().GetContext(context.Background(), &count, `
+SELECT COUNT(*)
+FROM watermill_events_to_forward
+WHERE payload->>'destination_topic' = $1
+  AND convert_from(decode(payload->>'payload', 'base64'), 'UTF8') LIKE '%' || $2 || '%'
+`, topic, ticketID)
+	require.NoError(t, err)
+
+	return count
+}
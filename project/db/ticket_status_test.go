@@ -0,0 +1,71 @@
+package db
+
+import (
+	"context"
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"tickets/internal/entities"
+	"time"
+)
+
+func TestTicket_status_transitions(t *testing.T) {
+	ctx := context.Background()
+	ticket := testTicketBookingConfirmed(watermill.NewUUID())
+	ticket.BookingID = watermill.NewUUID()
+
+	require.NoError(t, ticketRepo.SaveTicket(ctx, ticket))
+
+	got, err := ticketRepo.GetByID(ctx, ticket.TicketID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.TicketStatusConfirmed, got.Status)
+
+	canceled := entities.TicketBookingCanceled{
+		TicketID:      ticket.TicketID,
+		CustomerEmail: ticket.CustomerEmail,
+		Price:         ticket.Price,
+		BookingID:     ticket.BookingID,
+	}
+	require.NoError(t, ticketRepo.CancelTicket(ctx, canceled))
+
+	// redelivered confirmation must not bring the ticket back
+	require.NoError(t, ticketRepo.SaveTicket(ctx, ticket))
+
+	got, err = ticketRepo.GetByID(ctx, ticket.TicketID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.TicketStatusCanceled, got.Status)
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, ticketRepo.RefundTicket(ctx, ticket.TicketID, time.Now()))
+	}
+
+	// canceling a refunded ticket is ignored
+	require.NoError(t, ticketRepo.CancelTicket(ctx, canceled))
+
+	got, err = ticketRepo.GetByID(ctx, ticket.TicketID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.TicketStatusRefunded, got.Status)
+
+	refunded, err := ticketRepo.TicketList(ctx, entities.TicketFilter{
+		Status:    entities.TicketStatusRefunded,
+		BookingID: ticket.BookingID,
+	})
+	require.NoError(t, err)
+
+	var found bool
+	for _, listed := range refunded.Items {
+		if listed.TicketID == ticket.TicketID {
+			found = true
+			assert.NotNil(t, listed.ConfirmedAt)
+			assert.NotNil(t, listed.CanceledAt)
+			assert.NotNil(t, listed.RefundedAt)
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestTicket_refund_missing_ticket(t *testing.T) {
+	err := ticketRepo.RefundTicket(context.Background(), watermill.NewUUID(), time.Now())
+	assert.Error(t, err)
+}
@@ -3,23 +3,38 @@ package app
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+	"github.com/ThreeDotsLabs/watermill/components/cqrs"
 	"github.com/ThreeDotsLabs/watermill/message"
 	"github.com/jmoiron/sqlx"
 	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/sync/errgroup"
 	"net/http"
 	"os"
 	"os/signal"
+	"syscall"
 	broker2 "tickets/internal/broker"
 	"tickets/internal/broker/command"
+	"tickets/internal/broker/dedup"
 	"tickets/internal/broker/event"
 	"tickets/internal/broker/outbox"
+	"tickets/internal/broker/poison"
+	"tickets/internal/config"
+	"tickets/internal/health"
 	v1 "tickets/internal/http/v1"
+	"tickets/internal/metrics"
+	"tickets/internal/notifications"
+	"tickets/internal/render"
 	"tickets/internal/repository"
 	"tickets/internal/service"
+	"tickets/internal/service/idempotency"
+	"tickets/internal/service/refund"
+	"tickets/internal/tickettoken"
+	"tickets/internal/tracing"
 	"time"
 )
 
@@ -27,16 +42,25 @@ type App struct {
 	watermillRouter *message.Router
 	echoRouter      *echo.Echo
 	rdb             *redis.Client
+	deduplicator    *dedup.Deduplicator
+	keyCleaner      *idempotency.KeyCleaner
+	refundRetrier   *refund.Retrier
+	db              *sqlx.DB
+	httpAddr        string
+	drainTimeout    time.Duration
 }
 
 func Initialize(
+	cfg config.Config,
 	receiptsClient receiptsClient,
 	spreadsheetsClient spreadsheetsClient,
 	filesClient filesClient,
 	deadNationClient deadNationClient,
 	paymentClient paymentClient,
+	mailer notifications.Mailer,
 	redisClient *redis.Client,
 	db *sqlx.DB,
+	metricsRegistry *prometheus.Registry,
 ) *App {
 	// logger init
 	log.Init(logrus.InfoLevel)
@@ -47,6 +71,7 @@ func Initialize(
 
 	// publisher decorator
 	publisher = &log.CorrelationPublisherDecorator{Publisher: publisher}
+	publisher = tracing.PublisherDecorator{Publisher: publisher}
 
 	// event bus init
 	eventBus, err := event.NewEventBus(publisher)
@@ -60,44 +85,94 @@ func Initialize(
 		panic(err)
 	}
 
+	// dead-letter queue init
+	poisonQueue := poison.NewQueue(redisClient, publisher)
+
 	// repository init
 	repo := repository.NewRepository(db)
 
+	// signs the tokens printed on tickets and verifies them at check-in
+	ticketTokens := tickettoken.NewSigner(cfg.Tickets.TokenSecret)
+
 	// service init
-	serv := service.NewService(receiptsClient, spreadsheetsClient, filesClient, deadNationClient, paymentClient, repo)
+	serv := service.NewService(receiptsClient, spreadsheetsClient, filesClient, deadNationClient, paymentClient, poisonQueue, ticketTokens, repo)
+
+	// ticket rendering with signed tokens
+	ticketRenderer := render.NewRenderer(ticketTokens)
 
 	eventsHandler := event.NewHandler(
 		serv.DeadNationClient,
 		serv.SpreadsheetsClient,
 		serv.ReceiptsClient,
 		serv.FilesClient,
+		ticketRenderer,
 		serv.Ticket,
 		serv.Show,
 		serv.Booking,
 		eventBus,
+		commandBus,
+		cfg.Spreadsheets,
 	)
 
 	commandsHandler := command.NewHandler(
 		eventBus,
 		serv.ReceiptsClient,
 		serv.PaymentClient,
+		serv.Booking,
+		serv.Refund,
+		cfg.Refunds,
 	)
 
-	// handler init
-	handler := v1.NewHandler(eventBus, commandBus, serv, watermillLogger)
+	// customer notifications
+	notificationHandlers := notifications.NewNotifier(mailer, serv.Ticket, serv.Show).Handlers()
+
+	// processed events deduplication for ticket event and notification handlers, so customers don't get an email twice
+	deduplicator := dedup.NewDeduplicator(
+		repo.ProcessedEvents,
+		cfg.Broker.ConsumerGroupPrefix,
+		cfg.Broker.ProcessedEventsRetention,
+		append(handlerNames(eventsHandler.TicketEventHandlers()), handlerNames(notificationHandlers)...),
+	)
+
+	// expired Idempotency-Key responses cleanup
+	keyCleaner := idempotency.NewKeyCleaner(repo.Idempotency, cfg.HTTP.IdempotencyKeyRetention)
+
+	// failed refund steps retried after a backoff
+	refundRetrier := refund.NewRetrier(repo.Refund, commandBus, cfg.Refunds.RetryDelay)
+
+	// handler, HTTP and outbox lag metrics, served from the same registry
+	appMetrics := metrics.NewMetrics(metricsRegistry)
+	metricsRegistry.MustRegister(outbox.NewLagCollector(db))
 
 	// postgres subscriber
-	postgresSubscriber := outbox.NewPostgresSubscriber(db, watermillLogger)
+	postgresSubscriber := outbox.NewPostgresSubscriber(db, cfg.Outbox.PollInterval, watermillLogger)
 
 	// event processor config
-	eventProcessorConfig := event.NewEventProcessorConfig(redisClient, watermillLogger)
+	eventProcessorConfig := event.NewEventProcessorConfig(redisClient, cfg.Broker.ConsumerGroupPrefix, watermillLogger)
 
 	// command processor config
-	commandProcessorConfig := command.NewCommandProcessorConfig(redisClient, watermillLogger)
+	commandProcessorConfig := command.NewCommandProcessorConfig(redisClient, cfg.Broker.ConsumerGroupPrefix, watermillLogger)
+
+	// read model handlers
+	readModelHandlers := append(
+		event.OpsReadModelHandlers(repo.Ops),
+		event.ShowAvailabilityReadModelHandlers(repo.ShowAvailability)...,
+	)
+	readModelHandlers = append(readModelHandlers, event.ShowCheckInsReadModelHandlers(repo.ShowCheckIns)...)
+
+	// events store subscriber
+	eventsStoreSubscriber := event.NewEventsStoreSubscriber(redisClient, cfg.Broker.ConsumerGroupPrefix, watermillLogger)
 
 	// broker router init
-	brokerRouter := broker2.NewWatermillRouter(serv, postgresSubscriber, &commandsHandler, &eventsHandler, publisher, eventBus,
-		eventProcessorConfig, commandProcessorConfig, watermillLogger)
+	brokerRouter := broker2.NewWatermillRouter(serv, postgresSubscriber, &commandsHandler, &eventsHandler, readModelHandlers, notificationHandlers,
+		eventsStoreSubscriber, repo.Events, publisher, poisonQueue.Middleware(), deduplicator.Middleware, appMetrics, eventBus,
+		eventProcessorConfig, commandProcessorConfig, cfg.Broker.Retry, cfg.Shutdown.DrainTimeout, watermillLogger)
+
+	// readiness checks
+	readiness := health.NewChecker(db, redisClient, brokerRouter, cfg.Outbox.MaxLag)
+
+	// handler init
+	handler := v1.NewHandler(commandBus, serv, watermillLogger, readiness, appMetrics, metricsRegistry)
 
 	// set http routes
 	httpRouter := handler.SetRoutes()
@@ -106,59 +181,104 @@ func Initialize(
 		echoRouter:      httpRouter,
 		watermillRouter: brokerRouter,
 		rdb:             redisClient,
+		deduplicator:    deduplicator,
+		keyCleaner:      keyCleaner,
+		refundRetrier:   refundRetrier,
+		db:              db,
+		httpAddr:        cfg.HTTP.Addr,
+		drainTimeout:    cfg.Shutdown.DrainTimeout,
 	}
 }
 
-func (a *App) Start() {
+func handlerNames(handlers []cqrs.EventHandler) []string {
+	names := make([]string, 0, len(handlers))
+	for _, h := range handlers {
+		names = append(names, h.HandlerName())
+	}
+	return names
+}
 
-	ctx := context.Background()
-	ctx, cancel := signal.NotifyContext(ctx, os.Interrupt, os.Kill)
+// Start runs the app until SIGINT or SIGTERM.
+func (a *App) Start() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
-	g, ctx := errgroup.WithContext(ctx)
+	if err := a.Run(ctx); err != nil {
+		panic(err)
+	}
+}
 
-	//ctxWithCancel, cancel := context.WithCancel(ctx)
+// Run serves HTTP and handles messages until ctx is done, then shuts down in order:
+// HTTP stops accepting and finishes in-flight requests, the router closes its subscribers and waits
+// for in-flight handlers and the outbox forwarder, and only then Redis and Postgres are closed.
+func (a *App) Run(ctx context.Context) error {
+	g, gCtx := errgroup.WithContext(ctx)
 
 	g.Go(func() error {
-		err := a.watermillRouter.Run(ctx)
-		return err
+		// the router is closed explicitly during shutdown, after HTTP stops publishing
+		return a.watermillRouter.Run(context.WithoutCancel(gCtx))
 	})
 
 	g.Go(func() error {
-		<-a.watermillRouter.Running()
+		return a.deduplicator.Run(gCtx)
+	})
 
-		err := a.echoRouter.Start(":8080")
-		if err != nil || errors.Is(err, http.ErrServerClosed) {
-			return err
-		}
-		return nil
+	g.Go(func() error {
+		return a.keyCleaner.Run(gCtx)
 	})
 
 	g.Go(func() error {
-		<-ctx.Done()
+		return a.refundRetrier.Run(gCtx)
+	})
 
-		err := a.rdb.Close()
-		if err != nil {
-			return err
+	g.Go(func() error {
+		select {
+		case <-a.watermillRouter.Running():
+		case <-gCtx.Done():
+			return nil
 		}
 
-		ctx, cancel = context.WithTimeout(ctx, time.Second*30)
-		defer cancel()
-
-		err = a.echoRouter.Shutdown(ctx)
-		if err != nil {
+		err := a.echoRouter.Start(a.httpAddr)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			return err
 		}
+		return nil
+	})
 
-		err = a.watermillRouter.Close()
-		if err != nil {
-			return err
-		}
+	g.Go(func() error {
+		<-gCtx.Done()
 
-		return nil
+		return a.shutdown()
 	})
 
-	if err := g.Wait(); err != nil {
-		panic(err)
+	return g.Wait()
+}
+
+func (a *App) shutdown() error {
+	logrus.Info("Shutting down")
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.drainTimeout)
+	defer cancel()
+
+	var errs []error
+
+	if err := a.echoRouter.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("could not shut down http server: %w", err))
+	}
+
+	if err := a.watermillRouter.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("could not close router: %w", err))
 	}
+
+	if err := a.rdb.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("could not close redis client: %w", err))
+	}
+
+	if err := a.db.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("could not close db: %w", err))
+	}
+
+	logrus.Info("Shut down")
+
+	return errors.Join(errs...)
 }
@@ -15,7 +15,7 @@ type spreadsheetsClient interface {
 }
 
 type filesClient interface {
-	StoreTicketContent(ctx context.Context, ticket entities.TicketBookingConfirmed) error
+	StoreTicketContent(ctx context.Context, file entities.TicketFile) error
 }
 
 type deadNationClient interface {
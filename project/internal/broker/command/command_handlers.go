@@ -2,30 +2,120 @@ package command
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+	"github.com/sirupsen/logrus"
+	"tickets/internal/config"
 	"tickets/internal/entities"
+	"tickets/internal/repository/booking"
+	"time"
 )
 
+// RefundTicket voids the receipt and refunds the payment of the ticket.
+// The state of each step is stored, so a redelivered command continues from the step that failed.
+// A failed step counts as one attempt per delivery: the command is acked and the refund retrier sends it again
+// after a backoff. When the attempts run out, TicketRefundFailed is published and the refund waits for manual intervention.
+// Steps already done are not compensated: a voided receipt stays voided when the payment refund keeps failing,
+// and the only way forward is retrying the refund with POST /refunds/:ticket_id/retry, which continues with the payment.
 func (h *Handler) RefundTicket(ctx context.Context, command *entities.RefundTicket) error {
 	idempotencyKey := command.Header.IdempotencyKey
 	if idempotencyKey == "" {
 		return fmt.Errorf("idempotency key is required")
 	}
 
-	if err := h.receiptsServiceClient.PutVoidReceiptWithResponse(ctx, entities.VoidReceipt{
-		TicketID:       command.TicketID,
-		Reason:         "ticket refunded",
-		IdempotencyKey: idempotencyKey,
-	}); err != nil {
-		return fmt.Errorf("failed to void receipt: %w", err)
+	refund, err := h.refundService.StartRefund(ctx, command.TicketID, idempotencyKey)
+	if err != nil {
+		return err
+	}
+
+	logger := log.FromContext(ctx).WithFields(logrus.Fields{
+		"ticket_id": refund.TicketID,
+		"status":    refund.Status,
+	})
+
+	if refund.FailedAt != nil {
+		logger.Warn("Refund failed before, waiting for manual intervention")
+		return nil
+	}
+
+	if refund.Status == entities.RefundStatusCompleted {
+		return nil
+	}
+
+	if refund.Status == entities.RefundStatusStarted {
+		if err := h.receiptsServiceClient.PutVoidReceiptWithResponse(ctx, entities.VoidReceipt{
+			TicketID:       refund.TicketID,
+			Reason:         "ticket refunded",
+			IdempotencyKey: refund.IdempotencyKey,
+		}); err != nil {
+			return h.refundStepFailed(ctx, refund, fmt.Errorf("failed to void receipt: %w", err))
+		}
+
+		if err := h.refundService.MarkReceiptVoided(ctx, refund.TicketID); err != nil {
+			return fmt.Errorf("failed to save voided receipt: %w", err)
+		}
 	}
 
 	if err := h.paymentsServiceClient.PutRefundsWithResponse(ctx, entities.PaymentRefund{
-		TicketID:       command.TicketID,
+		TicketID:       refund.TicketID,
 		RefundReason:   "ticket refunded",
-		IdempotencyKey: idempotencyKey,
+		IdempotencyKey: refund.IdempotencyKey,
 	}); err != nil {
-		return fmt.Errorf("failed to refund payment: %w", err)
+		return h.refundStepFailed(ctx, refund, fmt.Errorf("failed to refund payment: %w", err))
+	}
+
+	return h.refundService.CompleteRefund(ctx, refund.TicketID)
+}
+
+// refundStepFailed stores the failure and acks the command, the next attempt is scheduled in the refund itself.
+func (h *Handler) refundStepFailed(ctx context.Context, refund entities.Refund, stepErr error) error {
+	refund, err := h.refundService.RecordFailure(
+		ctx,
+		refund.TicketID,
+		stepErr.Error(),
+		h.refunds.MaxAttempts,
+		refundRetryDelay(h.refunds, refund.Attempts),
+	)
+	if err != nil {
+		// the failure isn't stored, so the router retries the message
+		return errors.Join(stepErr, err)
+	}
+
+	logger := log.FromContext(ctx).WithFields(logrus.Fields{
+		"ticket_id": refund.TicketID,
+		"status":    refund.Status,
+		"attempts":  refund.Attempts,
+		"error":     stepErr,
+	})
+
+	if refund.FailedAt != nil {
+		logger.Error("Refund failed, manual intervention required")
+	} else {
+		logger.WithField("next_attempt_at", refund.NextAttemptAt).Warn("Refund step failed, retry scheduled")
+	}
+
+	return nil
+}
+
+// refundRetryDelay doubles the retry delay with every failed attempt, up to the max retry delay.
+func refundRetryDelay(refunds config.Refunds, failedAttempts int) time.Duration {
+	delay := refunds.RetryDelay
+	for i := 0; i < failedAttempts && delay < refunds.MaxRetryDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, refunds.MaxRetryDelay)
+}
+
+func (h *Handler) CancelBooking(ctx context.Context, command *entities.CancelBooking) error {
+	err := h.bookingService.CancelBooking(ctx, command.BookingID)
+	if errors.Is(err, booking.ErrBookingNotFound) {
+		// retrying won't help
+		log.FromContext(ctx).WithField("booking_id", command.BookingID).Warn("Booking to cancel not found")
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to cancel booking: %w", err)
 	}
 
 	return nil
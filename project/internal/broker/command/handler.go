@@ -3,7 +3,10 @@ package command
 import (
 	"context"
 	"github.com/ThreeDotsLabs/watermill/components/cqrs"
+	"github.com/google/uuid"
+	"tickets/internal/config"
 	"tickets/internal/entities"
+	"time"
 )
 
 type Handler struct {
@@ -11,9 +14,20 @@ type Handler struct {
 
 	receiptsServiceClient ReceiptsService
 	paymentsServiceClient PaymentsService
+	bookingService        BookingService
+	refundService         RefundService
+
+	refunds config.Refunds
 }
 
-func NewHandler(eventBus *cqrs.EventBus, receiptsServiceClient ReceiptsService, paymentsServiceClient PaymentsService) Handler {
+func NewHandler(
+	eventBus *cqrs.EventBus,
+	receiptsServiceClient ReceiptsService,
+	paymentsServiceClient PaymentsService,
+	bookingService BookingService,
+	refundService RefundService,
+	refunds config.Refunds,
+) Handler {
 	if eventBus == nil {
 		panic("eventBus is required")
 	}
@@ -23,11 +37,23 @@ func NewHandler(eventBus *cqrs.EventBus, receiptsServiceClient ReceiptsService,
 	if paymentsServiceClient == nil {
 		panic("paymentsServiceClient is required")
 	}
+	if bookingService == nil {
+		panic("bookingService is required")
+	}
+	if refundService == nil {
+		panic("refundService is required")
+	}
+	if refunds.MaxAttempts < 1 || refunds.RetryDelay <= 0 || refunds.MaxRetryDelay < refunds.RetryDelay {
+		panic("invalid refunds config")
+	}
 
 	handler := Handler{
 		eventBus:              eventBus,
 		receiptsServiceClient: receiptsServiceClient,
 		paymentsServiceClient: paymentsServiceClient,
+		bookingService:        bookingService,
+		refundService:         refundService,
+		refunds:               refunds,
 	}
 
 	return handler
@@ -36,6 +62,7 @@ func NewHandler(eventBus *cqrs.EventBus, receiptsServiceClient ReceiptsService,
 func (h *Handler) TicketCommandHandler() []cqrs.CommandHandler {
 	return []cqrs.CommandHandler{
 		cqrs.NewCommandHandler("RefundTicket", h.RefundTicket),
+		cqrs.NewCommandHandler("CancelBooking", h.CancelBooking),
 	}
 }
 
@@ -46,3 +73,14 @@ type ReceiptsService interface {
 type PaymentsService interface {
 	PutRefundsWithResponse(ctx context.Context, request entities.PaymentRefund) error
 }
+
+type BookingService interface {
+	CancelBooking(ctx context.Context, bookingID uuid.UUID) error
+}
+
+type RefundService interface {
+	StartRefund(ctx context.Context, ticketID string, idempotencyKey string) (entities.Refund, error)
+	MarkReceiptVoided(ctx context.Context, ticketID string) error
+	CompleteRefund(ctx context.Context, ticketID string) error
+	RecordFailure(ctx context.Context, ticketID string, reason string, maxAttempts int, retryDelay time.Duration) (entities.Refund, error)
+}
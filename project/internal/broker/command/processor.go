@@ -13,7 +13,7 @@ var marshaller = cqrs.JSONMarshaler{
 	GenerateName: cqrs.StructName,
 }
 
-func NewCommandProcessorConfig(redisClient *redis.Client, watermillLogger watermill.LoggerAdapter) cqrs.CommandProcessorConfig {
+func NewCommandProcessorConfig(redisClient *redis.Client, consumerGroupPrefix string, watermillLogger watermill.LoggerAdapter) cqrs.CommandProcessorConfig {
 	return cqrs.CommandProcessorConfig{
 		GenerateSubscribeTopic: func(params cqrs.CommandProcessorGenerateSubscribeTopicParams) (string, error) {
 			return fmt.Sprintf("commands.%s", params.CommandName), nil
@@ -21,7 +21,7 @@ func NewCommandProcessorConfig(redisClient *redis.Client, watermillLogger waterm
 		SubscriberConstructor: func(params cqrs.CommandProcessorSubscriberConstructorParams) (message.Subscriber, error) {
 			return redisstream.NewSubscriber(redisstream.SubscriberConfig{
 				Client:        redisClient,
-				ConsumerGroup: "svc-tickets." + params.HandlerName,
+				ConsumerGroup: consumerGroupPrefix + params.HandlerName,
 			}, watermillLogger)
 		},
 		Marshaler: marshaller,
@@ -0,0 +1,131 @@
+package dedup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"time"
+)
+
+const cleanupInterval = time.Hour
+
+type Store interface {
+	IsProcessed(ctx context.Context, consumerGroup, eventID string) (bool, error)
+	MarkProcessed(ctx context.Context, consumerGroup, eventID string) error
+	DeleteProcessedBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Deduplicator skips events that a handler has already processed, based on the EventHeader ID.
+// Events are marked as processed only after the handler succeeds, so a failed event is retried.
+// Duplicates redelivered after the retention window are processed again.
+// Processed events are tracked per consumer group, so instances with another consumer group prefix
+// process the same events on their own.
+type Deduplicator struct {
+	store               Store
+	consumerGroupPrefix string
+	retention           time.Duration
+	handlers            map[string]struct{}
+}
+
+func NewDeduplicator(store Store, consumerGroupPrefix string, retention time.Duration, handlerNames []string) *Deduplicator {
+	if store == nil {
+		panic("missing store")
+	}
+	if consumerGroupPrefix == "" {
+		panic("missing consumerGroupPrefix")
+	}
+	if retention <= 0 {
+		panic("retention must be positive")
+	}
+
+	handlers := make(map[string]struct{}, len(handlerNames))
+	for _, name := range handlerNames {
+		handlers[name] = struct{}{}
+	}
+
+	return &Deduplicator{
+		store:               store,
+		consumerGroupPrefix: consumerGroupPrefix,
+		retention:           retention,
+		handlers:            handlers,
+	}
+}
+
+// Middleware should be added after the retry middleware, so every attempt checks if
+// a concurrent delivery of the same event has already succeeded.
+func (d *Deduplicator) Middleware(h message.HandlerFunc) message.HandlerFunc {
+	return func(msg *message.Message) ([]*message.Message, error) {
+		handlerName := message.HandlerNameFromCtx(msg.Context())
+		if _, ok := d.handlers[handlerName]; !ok {
+			return h(msg)
+		}
+
+		eventID := eventIDFromPayload(msg.Payload)
+		if eventID == "" {
+			return h(msg)
+		}
+
+		ctx := msg.Context()
+		consumerGroup := d.consumerGroupPrefix + handlerName
+
+		processed, err := d.store.IsProcessed(ctx, consumerGroup, eventID)
+		if err != nil {
+			return nil, fmt.Errorf("could not check if event was processed: %w", err)
+		}
+		if processed {
+			log.FromContext(ctx).
+				WithField("handler", handlerName).
+				WithField("event_id", eventID).
+				Info("Skipping already processed event")
+			return nil, nil
+		}
+
+		msgs, err := h(msg)
+		if err != nil {
+			return msgs, err
+		}
+
+		// the handler already succeeded, failing here would only run it again
+		if err := d.store.MarkProcessed(ctx, consumerGroup, eventID); err != nil {
+			log.FromContext(ctx).WithError(err).Error("Failed to mark event as processed")
+		}
+
+		return msgs, nil
+	}
+}
+
+// Run removes processed events older than the retention window until ctx is done.
+func (d *Deduplicator) Run(ctx context.Context) error {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := d.store.DeleteProcessedBefore(ctx, time.Now().Add(-d.retention))
+		if err != nil {
+			log.FromContext(ctx).WithError(err).Error("Failed to delete old processed events")
+		} else if deleted > 0 {
+			log.FromContext(ctx).WithField("deleted", deleted).Info("Deleted old processed events")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func eventIDFromPayload(payload []byte) string {
+	var event struct {
+		Header struct {
+			ID string `json:"id"`
+		} `json:"header"`
+	}
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return ""
+	}
+
+	return event.Header.ID
+}
@@ -6,6 +6,10 @@ import (
 	"github.com/ThreeDotsLabs/watermill/message"
 )
 
+// AllEventsTopic receives a copy of every published event,
+// so consumers that need the whole history don't have to subscribe to each events.* topic.
+const AllEventsTopic = "events"
+
 func NewEventBus(pub message.Publisher) (*cqrs.EventBus, error) {
 	config := cqrs.EventBusConfig{
 		GeneratePublishTopic: func(params cqrs.GenerateEventPublishTopicParams) (string, error) {
@@ -16,5 +20,29 @@ func NewEventBus(pub message.Publisher) (*cqrs.EventBus, error) {
 		},
 	}
 
-	return cqrs.NewEventBusWithConfig(pub, config)
+	return cqrs.NewEventBusWithConfig(allEventsPublisher{Publisher: pub}, config)
+}
+
+// allEventsPublisher publishes the copy to AllEventsTopic before the event itself.
+// Through the outbox both are written in the same transaction. Published directly, a failure
+// after the copy makes the caller retry, and the repeated copy is ignored by the events store,
+// which deduplicates by event ID. The other order could lose the event from the store.
+type allEventsPublisher struct {
+	message.Publisher
+}
+
+func (p allEventsPublisher) Publish(topic string, messages ...*message.Message) error {
+	copies := make([]*message.Message, 0, len(messages))
+	for _, msg := range messages {
+		msgCopy := msg.Copy()
+		msgCopy.SetContext(msg.Context())
+
+		copies = append(copies, msgCopy)
+	}
+
+	if err := p.Publisher.Publish(AllEventsTopic, copies...); err != nil {
+		return err
+	}
+
+	return p.Publisher.Publish(topic, messages...)
 }
@@ -2,10 +2,14 @@ package event
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/ThreeDotsLabs/go-event-driven/common/log"
 	"github.com/ThreeDotsLabs/watermill/components/cqrs"
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
+	"strconv"
 	"tickets/internal/entities"
 )
 
@@ -15,7 +19,7 @@ func (h *Handler) TicketToPrint(ctx context.Context, event *entities.TicketBooki
 	}
 
 	// add ticket
-	if err := h.spreadsheetsService.AppendRow(ctx, "tickets-to-print", event.ToSpreadsheetTicketPayload()); err != nil {
+	if err := h.spreadsheetsService.AppendRow(ctx, h.spreadsheets.TicketsToPrint, event.ToSpreadsheetTicketPayload()); err != nil {
 		return err
 	}
 
@@ -31,17 +35,78 @@ func (h *Handler) SaveTicketInDB(ctx context.Context, event *entities.TicketBook
 	return nil
 }
 
+// StoreTicketContent renders the ticket as HTML and PDF and uploads both.
+// TicketPrinted is published only once the files are stored, so the print isn't recorded too early.
+// Like other derived events, it gets a new event ID and inherits the idempotency key.
+// A redelivered TicketBookingConfirmed is skipped by the dedup middleware; if it gets through,
+// the files API answers 409 and TicketPrinted is published again, MarkTicketPrinted keeps the first printed_at.
 func (h *Handler) StoreTicketContent(ctx context.Context, event *entities.TicketBookingConfirmed) error {
-	if err := h.filesAPI.StoreTicketContent(ctx, *event); err != nil {
+	show, err := h.showOfTicket(ctx, event.BookingID)
+	if err != nil {
 		return err
 	}
 
+	rendered, err := h.ticketRenderer.RenderTicket(*event, show)
+	if err != nil {
+		return fmt.Errorf("failed to render ticket: %w", err)
+	}
+
+	for _, file := range []entities.TicketFile{rendered.HTML, rendered.PDF} {
+		if err := h.filesAPI.StoreTicketContent(ctx, file); err != nil {
+			return fmt.Errorf("failed to store ticket content: %w", err)
+		}
+	}
+
+	if err := h.eventBus.Publish(ctx, entities.TicketPrinted{
+		Header:   entities.NewEventHeader(event.Header.IdempotencyKey),
+		TicketID: event.TicketID,
+		FileName: rendered.PDF.Name,
+	}); err != nil {
+		return fmt.Errorf("failed to publish TicketPrinted: %w", err)
+	}
+
 	return nil
 }
 
-func (h *Handler) DeleteTicket(ctx context.Context, event *entities.TicketBookingCanceled) error {
-	if err := h.ticketService.DeleteTicket(ctx, event.TicketID); err != nil {
-		return err
+// showOfTicket returns nil for tickets that weren't booked through us, they are printed without show details.
+func (h *Handler) showOfTicket(ctx context.Context, bookingID string) (*entities.Show, error) {
+	id, err := uuid.Parse(bookingID)
+	if err != nil {
+		return nil, nil
+	}
+
+	show, err := h.showService.ShowOfBooking(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get show of booking %s: %w", bookingID, err)
+	}
+
+	return show, nil
+}
+
+func (h *Handler) CancelTicketInDB(ctx context.Context, event *entities.TicketBookingCanceled) error {
+	if err := h.ticketService.CancelTicket(ctx, *event); err != nil {
+		return fmt.Errorf("failed to cancel ticket: %w", err)
+	}
+
+	return nil
+}
+
+func (h *Handler) MarkTicketPrinted(ctx context.Context, event *entities.TicketPrinted) error {
+	if err := h.ticketService.MarkTicketPrinted(ctx, event.TicketID, event.Header.OccurredAt()); err != nil {
+		return fmt.Errorf("failed to mark ticket as printed: %w", err)
+	}
+
+	return nil
+}
+
+func (h *Handler) MarkReceiptIssued(ctx context.Context, event *entities.TicketReceiptIssued) error {
+	issuedAt := event.IssuedAt
+	if issuedAt.IsZero() {
+		issuedAt = event.Header.OccurredAt()
+	}
+
+	if err := h.ticketService.MarkReceiptIssued(ctx, event.TicketID, issuedAt); err != nil {
+		return fmt.Errorf("failed to mark receipt as issued: %w", err)
 	}
 
 	return nil
@@ -52,9 +117,34 @@ func (h *Handler) TicketToRefund(ctx context.Context, event *entities.TicketBook
 		event.Price.Currency = "USD"
 	}
 
-	if err := h.spreadsheetsService.AppendRow(ctx, "tickets-to-refund", event.ToSpreadsheetTicketPayload()); err != nil {
+	if err := h.spreadsheetsService.AppendRow(ctx, h.spreadsheets.TicketsToRefund, event.ToSpreadsheetTicketPayload()); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (h *Handler) TicketRefundedToSheet(ctx context.Context, event *entities.TicketRefunded) error {
+	row := []string{event.TicketID, "", "", ""}
+
+	ticket, err := h.ticketService.TicketByID(ctx, event.TicketID)
+	if err == nil {
+		row = []string{ticket.TicketID, ticket.CustomerEmail, ticket.Price.Amount, ticket.Price.Currency}
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to get refunded ticket: %w", err)
+	}
+
+	if err := h.spreadsheetsService.AppendRow(ctx, h.spreadsheets.TicketsRefunded, row); err != nil {
 		return err
 	}
+
+	return nil
+}
+
+func (h *Handler) MarkTicketRefunded(ctx context.Context, event *entities.TicketRefunded) error {
+	if err := h.ticketService.MarkTicketRefunded(ctx, event.TicketID, event.Header.OccurredAt()); err != nil {
+		return fmt.Errorf("failed to mark ticket as refunded: %w", err)
+	}
+
 	return nil
 }
 
@@ -79,6 +169,58 @@ func (h *Handler) BookPlaceInDeadNation(ctx context.Context, event *entities.Boo
 	return nil
 }
 
+// BookingToRelease adds the canceled booking to the bookings to release sheet.
+// The Dead Nation API has no endpoint for canceling bookings, so ops release its seats there manually.
+func (h *Handler) BookingToRelease(ctx context.Context, event *entities.BookingCanceled) error {
+	show, err := h.showService.ShowByID(ctx, event.ShowID)
+	if err != nil {
+		return fmt.Errorf("failed to get show: %w", err)
+	}
+
+	row := []string{
+		event.BookingID.String(),
+		show.DeadNationID.String(),
+		strconv.Itoa(event.NumberOfTickets),
+		event.CustomerEmail,
+	}
+	if err := h.spreadsheetsService.AppendRow(ctx, h.spreadsheets.BookingsToRelease, row); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RefundTicketsOfCanceledShow sends RefundTicket for every confirmed ticket of the canceled show.
+// The idempotency key is derived from the ticket, so redelivery doesn't start a second refund.
+func (h *Handler) RefundTicketsOfCanceledShow(ctx context.Context, event *entities.ShowCanceled) error {
+	filter := entities.TicketFilter{
+		Status: entities.TicketStatusConfirmed,
+		ShowID: event.ShowID,
+		Limit:  entities.TicketsMaxLimit,
+	}
+
+	for {
+		page, err := h.ticketService.TicketList(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("failed to list tickets of canceled show: %w", err)
+		}
+
+		for _, ticket := range page.Items {
+			if err := h.commandBus.Send(ctx, entities.RefundTicket{
+				Header:   entities.NewCommandHeader("show-canceled-" + ticket.TicketID),
+				TicketID: ticket.TicketID,
+			}); err != nil {
+				return fmt.Errorf("failed to send RefundTicket command: %w", err)
+			}
+		}
+
+		if page.NextCursor == "" {
+			return nil
+		}
+		filter.Cursor = page.NextCursor
+	}
+}
+
 func (h *Handler) IssueReceipt(ctx context.Context, event *entities.TicketBookingConfirmed) error {
 	log.FromContext(ctx).Info("Issuing receipt")
 
@@ -94,7 +236,7 @@ func (h *Handler) IssueReceipt(ctx context.Context, event *entities.TicketBookin
 	}
 
 	if err = h.eventBus.Publish(ctx, entities.TicketReceiptIssued{
-		Header:        event.Header,
+		Header:        entities.NewEventHeader(event.Header.IdempotencyKey),
 		TicketID:      event.TicketID,
 		ReceiptNumber: resp.ReceiptNumber,
 		IssuedAt:      resp.IssuedAt,
@@ -112,8 +254,14 @@ func (h *Handler) TicketEventHandlers() []cqrs.EventHandler {
 		cqrs.NewEventHandler("TicketToPrint", h.TicketToPrint),
 		cqrs.NewEventHandler("TicketToRefund", h.TicketToRefund),
 		cqrs.NewEventHandler("SaveTicketInDB", h.SaveTicketInDB),
-		cqrs.NewEventHandler("DeleteTicket", h.DeleteTicket),
+		cqrs.NewEventHandler("CancelTicketInDB", h.CancelTicketInDB),
+		cqrs.NewEventHandler("MarkTicketPrinted", h.MarkTicketPrinted),
+		cqrs.NewEventHandler("MarkReceiptIssued", h.MarkReceiptIssued),
+		cqrs.NewEventHandler("TicketRefundedToSheet", h.TicketRefundedToSheet),
+		cqrs.NewEventHandler("MarkTicketRefunded", h.MarkTicketRefunded),
 		cqrs.NewEventHandler("StoreTicketContent", h.StoreTicketContent),
 		cqrs.NewEventHandler("BookPlaceInDeadNation", h.BookPlaceInDeadNation),
+		cqrs.NewEventHandler("BookingToRelease", h.BookingToRelease),
+		cqrs.NewEventHandler("RefundTicketsOfCanceledShow", h.RefundTicketsOfCanceledShow),
 	}
 }
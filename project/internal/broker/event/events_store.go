@@ -0,0 +1,45 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"tickets/internal/entities"
+)
+
+type EventsStore interface {
+	AppendEvent(ctx context.Context, event entities.StoredEvent) error
+}
+
+// AddEventsStoreHandler appends every event published on AllEventsTopic to the events store.
+// Events are deduplicated by the header ID, so redelivered events are stored only once.
+func AddEventsStoreHandler(router *message.Router, subscriber message.Subscriber, store EventsStore) {
+	router.AddNoPublisherHandler(
+		"EventsStore",
+		AllEventsTopic,
+		subscriber,
+		func(msg *message.Message) error {
+			var event struct {
+				Header entities.EventHeader `json:"header"`
+			}
+			if err := json.Unmarshal(msg.Payload, &event); err != nil {
+				return fmt.Errorf("could not unmarshal event header: %w", err)
+			}
+
+			eventID := event.Header.ID
+			if eventID == "" {
+				eventID = msg.UUID
+			}
+
+			return store.AppendEvent(msg.Context(), entities.StoredEvent{
+				EventID:        eventID,
+				EventName:      marshaller.NameFromMessage(msg),
+				Payload:        msg.Payload,
+				PublishedAt:    event.Header.PublishedAt,
+				IdempotencyKey: event.Header.IdempotencyKey,
+				CorrelationID:  msg.Metadata.Get("correlation_id"),
+			})
+		},
+	)
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"github.com/ThreeDotsLabs/watermill/components/cqrs"
 	"github.com/google/uuid"
+	"tickets/internal/config"
 	"tickets/internal/entities"
+	"time"
 )
 
 type Handler struct {
@@ -12,10 +14,13 @@ type Handler struct {
 	spreadsheetsService SpreadsheetsAPI
 	receiptsService     ReceiptsService
 	filesAPI            FilesAPI
+	ticketRenderer      TicketRenderer
 	ticketService       TicketService
 	showService         Show
 	bookingService      Booking
 	eventBus            *cqrs.EventBus
+	commandBus          *cqrs.CommandBus
+	spreadsheets        config.Spreadsheets
 }
 
 func NewHandler(
@@ -23,10 +28,13 @@ func NewHandler(
 	spreadsheetsService SpreadsheetsAPI,
 	receiptsService ReceiptsService,
 	filesAPI FilesAPI,
+	ticketRenderer TicketRenderer,
 	ticketService TicketService,
 	showService Show,
 	bookingService Booking,
 	eventBus *cqrs.EventBus,
+	commandBus *cqrs.CommandBus,
+	spreadsheets config.Spreadsheets,
 ) Handler {
 	if eventBus == nil {
 		panic("missing eventBus")
@@ -43,6 +51,9 @@ func NewHandler(
 	if filesAPI == nil {
 		panic("missing filesAPI")
 	}
+	if ticketRenderer == nil {
+		panic("missing ticketRenderer")
+	}
 	if eventBus == nil {
 		panic("missing eventBus")
 	}
@@ -55,13 +66,22 @@ func NewHandler(
 	if bookingService == nil {
 		panic("missing bookingService")
 	}
+	if commandBus == nil {
+		panic("missing commandBus")
+	}
 
 	return Handler{
 		deadNationAPI:       deadNationAPI,
 		spreadsheetsService: spreadsheetsService,
 		receiptsService:     receiptsService,
 		filesAPI:            filesAPI,
+		ticketRenderer:      ticketRenderer,
+		ticketService:       ticketService,
+		showService:         showService,
+		bookingService:      bookingService,
 		eventBus:            eventBus,
+		commandBus:          commandBus,
+		spreadsheets:        spreadsheets,
 	}
 }
 
@@ -74,13 +94,21 @@ type ReceiptsService interface {
 }
 
 type FilesAPI interface {
-	StoreTicketContent(ctx context.Context, ticket entities.TicketBookingConfirmed) error
+	StoreTicketContent(ctx context.Context, file entities.TicketFile) error
+}
+
+type TicketRenderer interface {
+	RenderTicket(ticket entities.TicketBookingConfirmed, show *entities.Show) (entities.RenderedTicket, error)
 }
 
 type TicketService interface {
 	SaveTicket(ctx context.Context, ticket entities.TicketBookingConfirmed) error
-	DeleteTicket(ctx context.Context, ticketID string) error
-	TicketList(ctx context.Context) ([]entities.TicketList, error)
+	CancelTicket(ctx context.Context, canceled entities.TicketBookingCanceled) error
+	TicketList(ctx context.Context, filter entities.TicketFilter) (entities.TicketPage, error)
+	TicketByID(ctx context.Context, ticketID string) (entities.Ticket, error)
+	MarkTicketRefunded(ctx context.Context, ticketID string, refundedAt time.Time) error
+	MarkTicketPrinted(ctx context.Context, ticketID string, printedAt time.Time) error
+	MarkReceiptIssued(ctx context.Context, ticketID string, issuedAt time.Time) error
 }
 
 type DeadNationAPI interface {
@@ -90,6 +118,7 @@ type DeadNationAPI interface {
 type Show interface {
 	NewShow(ctx context.Context, show entities.Show) (string, error)
 	ShowByID(ctx context.Context, showId uuid.UUID) (entities.Show, error)
+	ShowOfBooking(ctx context.Context, bookingID uuid.UUID) (*entities.Show, error)
 }
 
 type Booking interface {
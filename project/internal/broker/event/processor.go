@@ -13,7 +13,7 @@ var marshaller = cqrs.JSONMarshaler{
 	GenerateName: cqrs.StructName,
 }
 
-func NewEventProcessorConfig(redisClient *redis.Client, watermillLogger watermill.LoggerAdapter) cqrs.EventProcessorConfig {
+func NewEventProcessorConfig(redisClient *redis.Client, consumerGroupPrefix string, watermillLogger watermill.LoggerAdapter) cqrs.EventProcessorConfig {
 	return cqrs.EventProcessorConfig{
 		GenerateSubscribeTopic: func(params cqrs.EventProcessorGenerateSubscribeTopicParams) (string, error) {
 			return fmt.Sprintf("events.%s", params.EventName), nil
@@ -21,10 +21,22 @@ func NewEventProcessorConfig(redisClient *redis.Client, watermillLogger watermil
 		SubscriberConstructor: func(params cqrs.EventProcessorSubscriberConstructorParams) (message.Subscriber, error) {
 			return redisstream.NewSubscriber(redisstream.SubscriberConfig{
 				Client:        redisClient,
-				ConsumerGroup: "svc-tickets." + params.HandlerName,
+				ConsumerGroup: consumerGroupPrefix + params.HandlerName,
 			}, watermillLogger)
 		},
 		Marshaler: marshaller,
 		Logger:    watermillLogger,
 	}
 }
+
+func NewEventsStoreSubscriber(redisClient *redis.Client, consumerGroupPrefix string, watermillLogger watermill.LoggerAdapter) message.Subscriber {
+	sub, err := redisstream.NewSubscriber(redisstream.SubscriberConfig{
+		Client:        redisClient,
+		ConsumerGroup: consumerGroupPrefix + "EventsStore",
+	}, watermillLogger)
+	if err != nil {
+		panic(fmt.Errorf("failed to create events store subscriber: %w", err))
+	}
+
+	return sub
+}
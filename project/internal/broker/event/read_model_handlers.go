@@ -0,0 +1,51 @@
+package event
+
+import (
+	"context"
+	"github.com/ThreeDotsLabs/watermill/components/cqrs"
+	"tickets/internal/entities"
+)
+
+type OpsReadModel interface {
+	OnBookingMade(ctx context.Context, event *entities.BookingMade) error
+	OnTicketBookingConfirmed(ctx context.Context, event *entities.TicketBookingConfirmed) error
+	OnTicketPrinted(ctx context.Context, event *entities.TicketPrinted) error
+	OnTicketReceiptIssued(ctx context.Context, event *entities.TicketReceiptIssued) error
+	OnTicketRefunded(ctx context.Context, event *entities.TicketRefunded) error
+}
+
+// OpsReadModelHandlers registers every projection of the ops booking read model as a separate handler,
+// so each of them gets its own consumer group and can't be blocked by the others.
+func OpsReadModelHandlers(readModel OpsReadModel) []cqrs.EventHandler {
+	return []cqrs.EventHandler{
+		cqrs.NewEventHandler("OpsReadModel.OnBookingMade", readModel.OnBookingMade),
+		cqrs.NewEventHandler("OpsReadModel.OnTicketBookingConfirmed", readModel.OnTicketBookingConfirmed),
+		cqrs.NewEventHandler("OpsReadModel.OnTicketPrinted", readModel.OnTicketPrinted),
+		cqrs.NewEventHandler("OpsReadModel.OnTicketReceiptIssued", readModel.OnTicketReceiptIssued),
+		cqrs.NewEventHandler("OpsReadModel.OnTicketRefunded", readModel.OnTicketRefunded),
+	}
+}
+
+type ShowAvailabilityReadModel interface {
+	OnBookingMade(ctx context.Context, event *entities.BookingMade) error
+	OnTicketBookingCanceled(ctx context.Context, event *entities.TicketBookingCanceled) error
+	OnBookingCanceled(ctx context.Context, event *entities.BookingCanceled) error
+}
+
+func ShowAvailabilityReadModelHandlers(readModel ShowAvailabilityReadModel) []cqrs.EventHandler {
+	return []cqrs.EventHandler{
+		cqrs.NewEventHandler("ShowAvailabilityReadModel.OnBookingMade", readModel.OnBookingMade),
+		cqrs.NewEventHandler("ShowAvailabilityReadModel.OnTicketBookingCanceled", readModel.OnTicketBookingCanceled),
+		cqrs.NewEventHandler("ShowAvailabilityReadModel.OnBookingCanceled", readModel.OnBookingCanceled),
+	}
+}
+
+type ShowCheckInsReadModel interface {
+	OnTicketCheckedIn(ctx context.Context, event *entities.TicketCheckedIn) error
+}
+
+func ShowCheckInsReadModelHandlers(readModel ShowCheckInsReadModel) []cqrs.EventHandler {
+	return []cqrs.EventHandler{
+		cqrs.NewEventHandler("ShowCheckInsReadModel.OnTicketCheckedIn", readModel.OnTicketCheckedIn),
+	}
+}
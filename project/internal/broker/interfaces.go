@@ -17,7 +17,7 @@ type ServiceI interface {
 	AppendRow(ctx context.Context, spreadsheetName string, row []string) error
 
 	// Files
-	StoreTicketContent(ctx context.Context, ticket entities.TicketBookingConfirmed) error
+	StoreTicketContent(ctx context.Context, file entities.TicketFile) error
 
 	// Dead Nation
 	BookInDeadNation(ctx context.Context, request entities.DeadNationBooking) error
@@ -27,8 +27,8 @@ type ServiceI interface {
 
 	// Ticket
 	SaveTicket(ctx context.Context, ticket entities.TicketBookingConfirmed) error
-	DeleteTicket(ctx context.Context, ticketID string) error
-	TicketList(ctx context.Context) ([]entities.TicketList, error)
+	CancelTicket(ctx context.Context, canceled entities.TicketBookingCanceled) error
+	TicketList(ctx context.Context, filter entities.TicketFilter) (entities.TicketPage, error)
 
 	// Payment
 	//PutRefundsWithResponse(ctx context.Context, command entities.RefundTicket) error
@@ -0,0 +1,71 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	"time"
+)
+
+const undefinedTable = "42P01"
+
+// oldestUnforwarded returns the age of the oldest outbox message the forwarder hasn't acked yet.
+const oldestUnforwarded = `
+SELECT COALESCE(EXTRACT(EPOCH FROM now() - MIN(created_at)), 0)
+FROM watermill_` + outboxTopic + `
+WHERE "offset" > (SELECT COALESCE(MAX(offset_acked), 0) FROM watermill_offsets_` + outboxTopic + `)
+`
+
+var lagDesc = prometheus.NewDesc(
+	"tickets_outbox_lag_seconds",
+	"Age of the oldest message in the outbox that wasn't forwarded yet.",
+	nil, nil,
+)
+
+// LagCollector reports the outbox lag, it's queried on every scrape.
+type LagCollector struct {
+	db *sqlx.DB
+}
+
+func NewLagCollector(db *sqlx.DB) *LagCollector {
+	if db == nil {
+		panic("missing db")
+	}
+
+	return &LagCollector{db: db}
+}
+
+func (c *LagCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- lagDesc
+}
+
+func (c *LagCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	lag, err := Lag(ctx, c.db)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("Could not get outbox lag")
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(lagDesc, prometheus.GaugeValue, lag.Seconds())
+}
+
+// Lag returns the age of the oldest outbox message that wasn't forwarded yet.
+func Lag(ctx context.Context, db *sqlx.DB) (time.Duration, error) {
+	var seconds float64
+	if err := db.GetContext(ctx, &seconds, oldestUnforwarded); err != nil {
+		// the outbox tables are created with the first published message
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == undefinedTable {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return time.Duration(seconds * float64(time.Second)), nil
+}
@@ -8,6 +8,8 @@ import (
 	watermillSQL "github.com/ThreeDotsLabs/watermill-sql/v2/pkg/sql"
 	"github.com/ThreeDotsLabs/watermill/components/forwarder"
 	"github.com/ThreeDotsLabs/watermill/message"
+	"tickets/internal/broker/event"
+	"tickets/internal/tracing"
 )
 
 const outboxTopic = "events_to_forward"
@@ -29,12 +31,36 @@ func NewPublisherForDb(ctx context.Context, db *sql.Tx) (message.Publisher, erro
 	}
 
 	publisher = log.CorrelationPublisherDecorator{Publisher: publisher}
+	publisher = tracing.PublisherDecorator{Publisher: publisher}
 
 	publisher = forwarder.NewPublisher(publisher, forwarder.PublisherConfig{
 		ForwarderTopic: outboxTopic,
 	})
 
 	publisher = log.CorrelationPublisherDecorator{Publisher: publisher}
+	publisher = tracing.PublisherDecorator{Publisher: publisher}
 
 	return publisher, nil
 }
+
+// PublishInTx publishes the events to the outbox in the transaction,
+// the forwarder delivers them to the broker once it's committed.
+func PublishInTx(ctx context.Context, tx *sql.Tx, events ...any) error {
+	publisher, err := NewPublisherForDb(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	eventBus, err := event.NewEventBus(publisher)
+	if err != nil {
+		return fmt.Errorf("could not create outbox event bus: %w", err)
+	}
+
+	for _, e := range events {
+		if err := eventBus.Publish(ctx, e); err != nil {
+			return fmt.Errorf("could not publish %T: %w", e, err)
+		}
+	}
+
+	return nil
+}
@@ -8,11 +8,11 @@ import (
 	"time"
 )
 
-func NewPostgresSubscriber(db *sqlx.DB, logger watermill.LoggerAdapter) *watermillSQL.Subscriber {
+func NewPostgresSubscriber(db *sqlx.DB, pollInterval time.Duration, logger watermill.LoggerAdapter) *watermillSQL.Subscriber {
 	sub, err := watermillSQL.NewSubscriber(
 		db,
 		watermillSQL.SubscriberConfig{
-			PollInterval:     time.Millisecond * 100,
+			PollInterval:     pollInterval,
 			InitializeSchema: true,
 			SchemaAdapter:    watermillSQL.DefaultPostgreSQLSchema{},
 			OffsetsAdapter:   watermillSQL.DefaultPostgreSQLOffsetsAdapter{},
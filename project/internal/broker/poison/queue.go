@@ -0,0 +1,195 @@
+package poison
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+	"github.com/ThreeDotsLabs/watermill-redisstream/pkg/redisstream"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/ThreeDotsLabs/watermill/message/router/middleware"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"strings"
+	"tickets/internal/entities"
+	"time"
+)
+
+// Topic is the dead-letter topic, where messages are moved after all retries failed.
+const Topic = "dead_letter"
+
+// RequeuedForHandlerKey marks a requeued message, so only the handler that failed processes it again.
+const RequeuedForHandlerKey = "requeued_for_handler"
+
+// forwarderHandlerName is the name of the outbox forwarder handler.
+// Its messages come from Postgres, so they can't be requeued to Redis and are retried until they succeed.
+const forwarderHandlerName = "events_forwarder"
+
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+type Queue struct {
+	rdb       *redis.Client
+	publisher message.Publisher
+
+	unmarshaller redisstream.DefaultMarshallerUnmarshaller
+}
+
+func NewQueue(rdb *redis.Client, publisher message.Publisher) *Queue {
+	if rdb == nil {
+		panic("missing rdb")
+	}
+	if publisher == nil {
+		panic("missing publisher")
+	}
+
+	return &Queue{rdb: rdb, publisher: publisher}
+}
+
+// Middleware moves messages that failed in the next handlers to the dead-letter topic,
+// with the failure reason and handler name in metadata, and acks them.
+// It should be added before the retry middleware, so messages are moved only once retries run out.
+func (q *Queue) Middleware() message.HandlerMiddleware {
+	poisonQueue, err := middleware.PoisonQueue(q.publisher, Topic)
+	if err != nil {
+		panic(err)
+	}
+
+	return func(h message.HandlerFunc) message.HandlerFunc {
+		poisoned := poisonQueue(h)
+
+		return func(msg *message.Message) ([]*message.Message, error) {
+			handlerName := message.HandlerNameFromCtx(msg.Context())
+
+			requeuedFor := msg.Metadata.Get(RequeuedForHandlerKey)
+			if requeuedFor != "" && requeuedFor != handlerName {
+				// message was requeued for another handler, this one already processed it
+				return nil, nil
+			}
+
+			if handlerName == forwarderHandlerName {
+				return h(msg)
+			}
+
+			return poisoned(msg)
+		}
+	}
+}
+
+func (q *Queue) DeadLetters(ctx context.Context, limit int64) ([]entities.DeadLetter, error) {
+	entries, err := q.rdb.XRangeN(ctx, Topic, "-", "+", limit).Result()
+	if err != nil {
+		return nil, fmt.Errorf("could not read dead letters: %w", err)
+	}
+
+	deadLetters := make([]entities.DeadLetter, 0, len(entries))
+	for _, entry := range entries {
+		deadLetter, err := q.toDeadLetter(entry)
+		if err != nil {
+			return nil, err
+		}
+
+		deadLetters = append(deadLetters, deadLetter)
+	}
+
+	return deadLetters, nil
+}
+
+func (q *Queue) DeadLetterByID(ctx context.Context, id string) (entities.DeadLetter, error) {
+	entry, err := q.entryByID(ctx, id)
+	if err != nil {
+		return entities.DeadLetter{}, err
+	}
+
+	return q.toDeadLetter(entry)
+}
+
+// RequeueDeadLetter publishes the message back to its original topic and removes it from the dead-letter topic.
+// Only the handler that failed processes the requeued message.
+func (q *Queue) RequeueDeadLetter(ctx context.Context, id string) error {
+	entry, err := q.entryByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	msg, err := q.unmarshaller.Unmarshal(entry.Values)
+	if err != nil {
+		return fmt.Errorf("could not unmarshal dead letter %s: %w", id, err)
+	}
+
+	topic := msg.Metadata.Get(middleware.PoisonedTopicKey)
+	if topic == "" {
+		return fmt.Errorf("dead letter %s has no topic", id)
+	}
+
+	msg.Metadata.Set(RequeuedForHandlerKey, msg.Metadata.Get(middleware.PoisonedHandlerKey))
+	for _, key := range []string{
+		middleware.ReasonForPoisonedKey,
+		middleware.PoisonedTopicKey,
+		middleware.PoisonedHandlerKey,
+		middleware.PoisonedSubscriberKey,
+	} {
+		delete(msg.Metadata, key)
+	}
+	// the publisher decorators would replace the original correlation ID and trace with the admin request's,
+	// the message keeps them in its metadata
+	msg.SetContext(log.ContextWithCorrelationID(context.Background(), msg.Metadata.Get("correlation_id")))
+
+	if err := q.publisher.Publish(topic, msg); err != nil {
+		return fmt.Errorf("could not requeue dead letter %s to %s: %w", id, topic, err)
+	}
+
+	return q.DeleteDeadLetter(ctx, id)
+}
+
+func (q *Queue) DeleteDeadLetter(ctx context.Context, id string) error {
+	deleted, err := q.rdb.XDel(ctx, Topic, id).Result()
+	if err != nil {
+		return fmt.Errorf("could not delete dead letter %s: %w", id, err)
+	}
+	if deleted == 0 {
+		return ErrDeadLetterNotFound
+	}
+
+	return nil
+}
+
+func (q *Queue) entryByID(ctx context.Context, id string) (redis.XMessage, error) {
+	entries, err := q.rdb.XRange(ctx, Topic, id, id).Result()
+	if err != nil {
+		return redis.XMessage{}, fmt.Errorf("could not read dead letter %s: %w", id, err)
+	}
+	if len(entries) == 0 {
+		return redis.XMessage{}, ErrDeadLetterNotFound
+	}
+
+	return entries[0], nil
+}
+
+func (q *Queue) toDeadLetter(entry redis.XMessage) (entities.DeadLetter, error) {
+	msg, err := q.unmarshaller.Unmarshal(entry.Values)
+	if err != nil {
+		return entities.DeadLetter{}, fmt.Errorf("could not unmarshal dead letter %s: %w", entry.ID, err)
+	}
+
+	return entities.DeadLetter{
+		ID:          entry.ID,
+		MessageUUID: msg.UUID,
+		Topic:       msg.Metadata.Get(middleware.PoisonedTopicKey),
+		Handler:     msg.Metadata.Get(middleware.PoisonedHandlerKey),
+		Subscriber:  msg.Metadata.Get(middleware.PoisonedSubscriberKey),
+		Reason:      msg.Metadata.Get(middleware.ReasonForPoisonedKey),
+		Payload:     string(msg.Payload),
+		Metadata:    msg.Metadata,
+		PoisonedAt:  streamIDTime(entry.ID),
+	}, nil
+}
+
+// streamIDTime returns the time encoded in the Redis stream entry ID (<milliseconds>-<sequence>).
+func streamIDTime(id string) time.Time {
+	ms, err := strconv.ParseInt(strings.SplitN(id, "-", 2)[0], 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return time.UnixMilli(ms).UTC()
+}
@@ -9,27 +9,45 @@ import (
 	"tickets/internal/broker/command"
 	"tickets/internal/broker/event"
 	"tickets/internal/broker/outbox"
+	"tickets/internal/config"
+	"tickets/internal/metrics"
+	"tickets/internal/tracing"
 	"time"
 )
 
 type broker struct {
 	eventHandler     *event.Handler
+	readModels       []cqrs.EventHandler
+	notifications    []cqrs.EventHandler
 	commandHandler   *command.Handler
 	watermillLogger  watermill.LoggerAdapter
 	router           *message.Router
 	eventProcessor   *cqrs.EventProcessor
 	eventPublisher   *cqrs.EventBus
 	commandProcessor *cqrs.CommandProcessor
+	poisonQueue      message.HandlerMiddleware
+	deduplicator     message.HandlerMiddleware
+	metrics          *metrics.Metrics
+	retry            config.Retry
 }
 
 func NewWatermillRouter(service ServiceI,
 	postgresSubscriber message.Subscriber,
 	commandHandler *command.Handler,
 	eventHandler *event.Handler,
+	readModelHandlers []cqrs.EventHandler,
+	notificationHandlers []cqrs.EventHandler,
+	eventsStoreSubscriber message.Subscriber,
+	eventsStore event.EventsStore,
 	publisher message.Publisher,
+	poisonQueue message.HandlerMiddleware,
+	deduplicator message.HandlerMiddleware,
+	routerMetrics *metrics.Metrics,
 	eventPublisher *cqrs.EventBus,
 	eventProcessorConfig cqrs.EventProcessorConfig,
 	commandProcessorConfig cqrs.CommandProcessorConfig,
+	retry config.Retry,
+	closeTimeout time.Duration,
 	watermillLogger watermill.LoggerAdapter,
 ) *message.Router {
 	// validate
@@ -39,29 +57,65 @@ func NewWatermillRouter(service ServiceI,
 	if postgresSubscriber == nil {
 		panic("missing postgresSubscriber")
 	}
+	if len(readModelHandlers) == 0 {
+		panic("missing readModelHandlers")
+	}
+	if len(notificationHandlers) == 0 {
+		panic("missing notificationHandlers")
+	}
+	if eventsStoreSubscriber == nil {
+		panic("missing eventsStoreSubscriber")
+	}
+	if eventsStore == nil {
+		panic("missing eventsStore")
+	}
 	if publisher == nil {
 		panic("missing publisher")
 	}
+	if poisonQueue == nil {
+		panic("missing poisonQueue")
+	}
+	if deduplicator == nil {
+		panic("missing deduplicator")
+	}
+	if routerMetrics == nil {
+		panic("missing routerMetrics")
+	}
 
 	if eventPublisher == nil {
 		panic("missing publisher")
 	}
 
-	router, err := message.NewRouter(message.RouterConfig{}, watermillLogger)
+	router, err := message.NewRouter(message.RouterConfig{
+		// in-flight handlers, including the outbox forwarder, get this long to finish on Close
+		CloseTimeout: closeTimeout,
+	}, watermillLogger)
 	if err != nil {
 		panic(err)
 	}
 
 	outbox.AddForwarderHandler(postgresSubscriber, publisher, router, watermillLogger)
 
+	event.AddEventsStoreHandler(router, eventsStoreSubscriber, eventsStore)
+
 	broker := &broker{
 		watermillLogger: watermillLogger,
 		router:          router,
+		poisonQueue:     poisonQueue,
+		deduplicator:    deduplicator,
+		metrics:         routerMetrics,
+		retry:           retry,
 	}
 
 	// initialize event handlers
 	broker.eventHandler = eventHandler
 
+	// initialize read model handlers
+	broker.readModels = readModelHandlers
+
+	// initialize notification handlers
+	broker.notifications = notificationHandlers
+
 	// initialize command handlers
 	broker.commandHandler = commandHandler
 
@@ -96,6 +150,20 @@ func (b *broker) setEventHandlers() {
 	if err != nil {
 		panic(err)
 	}
+
+	err = b.eventProcessor.AddHandlers(
+		b.readModels...,
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	err = b.eventProcessor.AddHandlers(
+		b.notifications...,
+	)
+	if err != nil {
+		panic(err)
+	}
 }
 
 func (b *broker) setCommandHandlers() {
@@ -110,11 +178,23 @@ func (b *broker) setCommandHandlers() {
 func (b *broker) setMiddlewares() {
 	// Retry failed messages
 	retryMiddleware := middleware.Retry{
-		MaxRetries:      10,
-		InitialInterval: time.Millisecond * 100,
-		MaxInterval:     time.Second,
-		Multiplier:      2,
+		MaxRetries:      b.retry.MaxRetries,
+		InitialInterval: b.retry.InitialInterval,
+		MaxInterval:     b.retry.MaxInterval,
+		Multiplier:      b.retry.Multiplier,
 		Logger:          b.watermillLogger,
 	}
-	b.router.AddMiddleware(middleware.Recoverer, PropagateCorrelationID, middleware.CorrelationID, LoggingMiddleware, retryMiddleware.Middleware)
+	b.router.AddMiddleware(
+		middleware.Recoverer,
+		tracing.Middleware,
+		PropagateCorrelationID,
+		middleware.CorrelationID,
+		LoggingMiddleware,
+		// moves messages to the dead-letter topic once all retries failed
+		b.poisonQueue,
+		b.metrics.Middleware,
+		b.metrics.CountRetries(retryMiddleware),
+		// skips events already processed by the handler
+		b.deduplicator,
+	)
 }
@@ -0,0 +1,374 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"strconv"
+	"time"
+)
+
+// FileEnv points to an optional YAML config file, values from env override the file.
+const FileEnv = "CONFIG_FILE"
+
+type Config struct {
+	HTTP          HTTP          `yaml:"http"`
+	GatewayAddr   string        `yaml:"gateway_addr"`
+	Redis         Redis         `yaml:"redis"`
+	Postgres      Postgres      `yaml:"postgres"`
+	Broker        Broker        `yaml:"broker"`
+	Outbox        Outbox        `yaml:"outbox"`
+	Spreadsheets  Spreadsheets  `yaml:"spreadsheets"`
+	Tickets       Tickets       `yaml:"tickets"`
+	Refunds       Refunds       `yaml:"refunds"`
+	Notifications Notifications `yaml:"notifications"`
+	Tracing       Tracing       `yaml:"tracing"`
+	Shutdown      Shutdown      `yaml:"shutdown"`
+}
+
+type HTTP struct {
+	Addr string `yaml:"addr"`
+	// IdempotencyKeyRetention is how long Idempotency-Key responses are kept.
+	IdempotencyKeyRetention time.Duration `yaml:"idempotency_key_retention"`
+}
+
+type Redis struct {
+	Addr string `yaml:"addr"`
+}
+
+type Postgres struct {
+	URL string `yaml:"url"`
+}
+
+type Broker struct {
+	// ConsumerGroupPrefix is prepended to handler names to get their consumer groups.
+	// Changing it makes every handler consume its topics from the beginning.
+	ConsumerGroupPrefix      string        `yaml:"consumer_group_prefix"`
+	Retry                    Retry         `yaml:"retry"`
+	ProcessedEventsRetention time.Duration `yaml:"processed_events_retention"`
+}
+
+type Retry struct {
+	MaxRetries      int           `yaml:"max_retries"`
+	InitialInterval time.Duration `yaml:"initial_interval"`
+	MaxInterval     time.Duration `yaml:"max_interval"`
+	Multiplier      float64       `yaml:"multiplier"`
+}
+
+type Outbox struct {
+	PollInterval time.Duration `yaml:"poll_interval"`
+	// MaxLag is the forwarding lag above which the instance reports it isn't ready.
+	MaxLag time.Duration `yaml:"max_lag"`
+}
+
+type Spreadsheets struct {
+	TicketsToPrint  string `yaml:"tickets_to_print"`
+	TicketsToRefund string `yaml:"tickets_to_refund"`
+	TicketsRefunded string `yaml:"tickets_refunded"`
+	// BookingsToRelease lists canceled bookings whose seats ops have to release in Dead Nation.
+	BookingsToRelease string `yaml:"bookings_to_release"`
+}
+
+type Tickets struct {
+	// TokenSecret signs the tokens printed on tickets, changing it invalidates every printed ticket.
+	TokenSecret string `yaml:"token_secret"`
+}
+
+type Refunds struct {
+	// MaxAttempts is how many deliveries of a refund may fail before it waits for manual intervention.
+	MaxAttempts int `yaml:"max_attempts"`
+	// RetryDelay is how long a failed refund waits before it's retried, doubled after each failure up to MaxRetryDelay.
+	RetryDelay    time.Duration `yaml:"retry_delay"`
+	MaxRetryDelay time.Duration `yaml:"max_retry_delay"`
+}
+
+type Notifications struct {
+	// Mailer is one of: file, smtp.
+	Mailer string `yaml:"mailer"`
+	From   string `yaml:"from"`
+	// File is where the file mailer appends emails.
+	File string `yaml:"file"`
+	SMTP SMTP   `yaml:"smtp"`
+}
+
+type SMTP struct {
+	Addr     string `yaml:"addr"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	// Timeout limits sending a single email, including connecting to the server.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+type Tracing struct {
+	// Exporter is one of: none, otlp, file, stdout.
+	Exporter string `yaml:"exporter"`
+	File     string `yaml:"file"`
+}
+
+type Shutdown struct {
+	// DrainTimeout limits how long in-flight HTTP requests and messages are waited for on shutdown.
+	DrainTimeout time.Duration `yaml:"drain_timeout"`
+}
+
+func Default() Config {
+	return Config{
+		HTTP: HTTP{
+			Addr:                    ":8080",
+			IdempotencyKeyRetention: 24 * time.Hour,
+		},
+		Broker: Broker{
+			ConsumerGroupPrefix: "svc-tickets.",
+			Retry: Retry{
+				MaxRetries:      10,
+				InitialInterval: 100 * time.Millisecond,
+				MaxInterval:     time.Second,
+				Multiplier:      2,
+			},
+			ProcessedEventsRetention: 7 * 24 * time.Hour,
+		},
+		Outbox: Outbox{
+			PollInterval: 100 * time.Millisecond,
+			MaxLag:       30 * time.Second,
+		},
+		Spreadsheets: Spreadsheets{
+			TicketsToPrint:    "tickets-to-print",
+			TicketsToRefund:   "tickets-to-refund",
+			TicketsRefunded:   "tickets-refunded",
+			BookingsToRelease: "bookings-to-release",
+		},
+		Refunds: Refunds{
+			MaxAttempts:   8,
+			RetryDelay:    time.Minute,
+			MaxRetryDelay: time.Hour,
+		},
+		Tracing: Tracing{
+			Exporter: "none",
+			File:     "traces.jsonl",
+		},
+		Notifications: Notifications{
+			Mailer: "file",
+			From:   "tickets@example.com",
+			File:   "notifications.jsonl",
+			SMTP: SMTP{
+				Timeout: 10 * time.Second,
+			},
+		},
+		Shutdown: Shutdown{
+			DrainTimeout: 30 * time.Second,
+		},
+	}
+}
+
+// Load returns the defaults overridden by the CONFIG_FILE YAML file, if set, and then by env.
+func Load() (Config, error) {
+	cfg := Default()
+
+	if path := os.Getenv(FileEnv); path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return Config{}, err
+		}
+	}
+
+	if err := cfg.loadEnv(); err != nil {
+		return Config{}, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("could not open config file: %w", err)
+	}
+	defer f.Close()
+
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(c); err != nil {
+		return fmt.Errorf("could not parse config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func (c *Config) loadEnv() error {
+	var errs []error
+
+	setString := func(env string, dst *string) {
+		if v, ok := os.LookupEnv(env); ok {
+			*dst = v
+		}
+	}
+	setDuration := func(env string, dst *time.Duration) {
+		if v, ok := os.LookupEnv(env); ok {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", env, err))
+				return
+			}
+			*dst = d
+		}
+	}
+	setInt := func(env string, dst *int) {
+		if v, ok := os.LookupEnv(env); ok {
+			i, err := strconv.Atoi(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", env, err))
+				return
+			}
+			*dst = i
+		}
+	}
+	setFloat := func(env string, dst *float64) {
+		if v, ok := os.LookupEnv(env); ok {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", env, err))
+				return
+			}
+			*dst = f
+		}
+	}
+
+	setString("HTTP_ADDR", &c.HTTP.Addr)
+	setDuration("IDEMPOTENCY_KEY_RETENTION", &c.HTTP.IdempotencyKeyRetention)
+	setString("GATEWAY_ADDR", &c.GatewayAddr)
+	setString("REDIS_ADDR", &c.Redis.Addr)
+	setString("POSTGRES_URL", &c.Postgres.URL)
+
+	setString("CONSUMER_GROUP_PREFIX", &c.Broker.ConsumerGroupPrefix)
+	setInt("RETRY_MAX_RETRIES", &c.Broker.Retry.MaxRetries)
+	setDuration("RETRY_INITIAL_INTERVAL", &c.Broker.Retry.InitialInterval)
+	setDuration("RETRY_MAX_INTERVAL", &c.Broker.Retry.MaxInterval)
+	setFloat("RETRY_MULTIPLIER", &c.Broker.Retry.Multiplier)
+	setDuration("PROCESSED_EVENTS_RETENTION", &c.Broker.ProcessedEventsRetention)
+
+	setDuration("OUTBOX_POLL_INTERVAL", &c.Outbox.PollInterval)
+	setDuration("OUTBOX_MAX_LAG", &c.Outbox.MaxLag)
+
+	setString("SPREADSHEET_TICKETS_TO_PRINT", &c.Spreadsheets.TicketsToPrint)
+	setString("SPREADSHEET_TICKETS_TO_REFUND", &c.Spreadsheets.TicketsToRefund)
+	setString("SPREADSHEET_TICKETS_REFUNDED", &c.Spreadsheets.TicketsRefunded)
+	setString("SPREADSHEET_BOOKINGS_TO_RELEASE", &c.Spreadsheets.BookingsToRelease)
+
+	setString("TICKET_TOKEN_SECRET", &c.Tickets.TokenSecret)
+
+	setInt("REFUND_MAX_ATTEMPTS", &c.Refunds.MaxAttempts)
+	setDuration("REFUND_RETRY_DELAY", &c.Refunds.RetryDelay)
+	setDuration("REFUND_MAX_RETRY_DELAY", &c.Refunds.MaxRetryDelay)
+
+	setString("NOTIFICATIONS_MAILER", &c.Notifications.Mailer)
+	setString("NOTIFICATIONS_FROM", &c.Notifications.From)
+	setString("NOTIFICATIONS_FILE", &c.Notifications.File)
+	setString("SMTP_ADDR", &c.Notifications.SMTP.Addr)
+	setString("SMTP_USERNAME", &c.Notifications.SMTP.Username)
+	setString("SMTP_PASSWORD", &c.Notifications.SMTP.Password)
+	setDuration("SMTP_TIMEOUT", &c.Notifications.SMTP.Timeout)
+
+	setString("OTEL_TRACES_EXPORTER", &c.Tracing.Exporter)
+	setString("OTEL_TRACES_FILE", &c.Tracing.File)
+
+	setDuration("SHUTDOWN_DRAIN_TIMEOUT", &c.Shutdown.DrainTimeout)
+
+	return errors.Join(errs...)
+}
+
+func (c Config) Validate() error {
+	var errs []error
+
+	required := []struct {
+		name  string
+		value string
+	}{
+		{"http.addr", c.HTTP.Addr},
+		{"gateway_addr", c.GatewayAddr},
+		{"redis.addr", c.Redis.Addr},
+		{"postgres.url", c.Postgres.URL},
+		{"broker.consumer_group_prefix", c.Broker.ConsumerGroupPrefix},
+		{"spreadsheets.tickets_to_print", c.Spreadsheets.TicketsToPrint},
+		{"spreadsheets.tickets_to_refund", c.Spreadsheets.TicketsToRefund},
+		{"spreadsheets.tickets_refunded", c.Spreadsheets.TicketsRefunded},
+		{"spreadsheets.bookings_to_release", c.Spreadsheets.BookingsToRelease},
+		{"tickets.token_secret", c.Tickets.TokenSecret},
+		{"notifications.from", c.Notifications.From},
+	}
+	for _, field := range required {
+		if field.value == "" {
+			errs = append(errs, fmt.Errorf("%s is required", field.name))
+		}
+	}
+
+	positive := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"http.idempotency_key_retention", c.HTTP.IdempotencyKeyRetention},
+		{"broker.retry.initial_interval", c.Broker.Retry.InitialInterval},
+		{"broker.retry.max_interval", c.Broker.Retry.MaxInterval},
+		{"broker.processed_events_retention", c.Broker.ProcessedEventsRetention},
+		{"outbox.poll_interval", c.Outbox.PollInterval},
+		{"outbox.max_lag", c.Outbox.MaxLag},
+		{"refunds.retry_delay", c.Refunds.RetryDelay},
+		{"refunds.max_retry_delay", c.Refunds.MaxRetryDelay},
+		{"shutdown.drain_timeout", c.Shutdown.DrainTimeout},
+	}
+	for _, field := range positive {
+		if field.value <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive", field.name))
+		}
+	}
+
+	if c.Broker.Retry.MaxRetries < 0 {
+		errs = append(errs, errors.New("broker.retry.max_retries can't be negative"))
+	}
+	if c.Broker.Retry.Multiplier < 1 {
+		errs = append(errs, errors.New("broker.retry.multiplier must be at least 1"))
+	}
+	if c.Broker.Retry.MaxInterval < c.Broker.Retry.InitialInterval {
+		errs = append(errs, errors.New("broker.retry.max_interval can't be lower than initial_interval"))
+	}
+
+	if c.Refunds.MaxAttempts < 1 {
+		errs = append(errs, errors.New("refunds.max_attempts must be at least 1"))
+	}
+	if c.Refunds.MaxRetryDelay < c.Refunds.RetryDelay {
+		errs = append(errs, errors.New("refunds.max_retry_delay can't be lower than retry_delay"))
+	}
+
+	switch c.Notifications.Mailer {
+	case "file":
+		if c.Notifications.File == "" {
+			errs = append(errs, errors.New("notifications.file is required by the file mailer"))
+		}
+	case "smtp":
+		if c.Notifications.SMTP.Addr == "" {
+			errs = append(errs, errors.New("notifications.smtp.addr is required by the smtp mailer"))
+		}
+		if c.Notifications.SMTP.Timeout <= 0 {
+			errs = append(errs, errors.New("notifications.smtp.timeout must be positive"))
+		}
+	case "":
+		errs = append(errs, errors.New("notifications.mailer is required, one of: file, smtp"))
+	default:
+		errs = append(errs, fmt.Errorf("unknown notifications.mailer %q", c.Notifications.Mailer))
+	}
+
+	switch c.Tracing.Exporter {
+	case "", "none", "otlp", "file", "stdout":
+	default:
+		errs = append(errs, fmt.Errorf("unknown tracing.exporter %q", c.Tracing.Exporter))
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	return nil
+}
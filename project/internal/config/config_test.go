@@ -0,0 +1,348 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	testCases := []struct {
+		name string
+		file string
+		env  map[string]string
+
+		expected      func(cfg *Config)
+		expectedError string
+	}{
+		{
+			name:     "defaults",
+			expected: func(cfg *Config) {},
+		},
+		{
+			name: "file overrides defaults",
+			file: `
+http:
+  addr: ":9000"
+broker:
+  retry:
+    max_retries: 3
+outbox:
+  poll_interval: 1s
+notifications:
+  smtp:
+    timeout: 5s
+`,
+			expected: func(cfg *Config) {
+				cfg.HTTP.Addr = ":9000"
+				cfg.Broker.Retry.MaxRetries = 3
+				cfg.Outbox.PollInterval = time.Second
+				cfg.Notifications.SMTP.Timeout = 5 * time.Second
+			},
+		},
+		{
+			name: "env overrides file",
+			file: `
+http:
+  addr: ":9000"
+broker:
+  retry:
+    max_retries: 3
+    multiplier: 1.5
+outbox:
+  poll_interval: 1s
+`,
+			env: map[string]string{
+				"HTTP_ADDR":            ":9001",
+				"RETRY_MAX_RETRIES":    "5",
+				"RETRY_MULTIPLIER":     "3",
+				"OUTBOX_POLL_INTERVAL": "2s",
+			},
+			expected: func(cfg *Config) {
+				cfg.HTTP.Addr = ":9001"
+				cfg.Broker.Retry.MaxRetries = 5
+				cfg.Broker.Retry.Multiplier = 3
+				cfg.Outbox.PollInterval = 2 * time.Second
+			},
+		},
+		{
+			name:          "unknown file key",
+			file:          "htp:\n  addr: \":9000\"\n",
+			expectedError: "could not parse config file",
+		},
+		{
+			name:          "invalid env duration",
+			env:           map[string]string{"OUTBOX_POLL_INTERVAL": "soon"},
+			expectedError: "OUTBOX_POLL_INTERVAL",
+		},
+		{
+			name:          "invalid env number",
+			env:           map[string]string{"RETRY_MAX_RETRIES": "many"},
+			expectedError: "RETRY_MAX_RETRIES",
+		},
+		{
+			name:          "invalid result",
+			env:           map[string]string{"NOTIFICATIONS_MAILER": "pigeon"},
+			expectedError: `unknown notifications.mailer "pigeon"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, env := range []string{
+				FileEnv,
+				"NOTIFICATIONS_MAILER",
+				"HTTP_ADDR",
+				"RETRY_MAX_RETRIES",
+				"RETRY_MULTIPLIER",
+				"OUTBOX_POLL_INTERVAL",
+				"SMTP_TIMEOUT",
+			} {
+				unsetEnv(t, env)
+			}
+
+			t.Setenv("GATEWAY_ADDR", "gateway:8080")
+			t.Setenv("REDIS_ADDR", "redis:6379")
+			t.Setenv("POSTGRES_URL", "postgres://postgres@postgres/db")
+			t.Setenv("TICKET_TOKEN_SECRET", "secret")
+
+			if tc.file != "" {
+				path := filepath.Join(t.TempDir(), "config.yaml")
+				require.NoError(t, os.WriteFile(path, []byte(tc.file), 0o600))
+				t.Setenv(FileEnv, path)
+			}
+			for env, value := range tc.env {
+				t.Setenv(env, value)
+			}
+
+			cfg, err := Load()
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+
+			expected := validConfig()
+			tc.expected(&expected)
+
+			assert.Equal(t, expected, cfg)
+		})
+	}
+}
+
+func TestDefault_mailer_writes_to_file(t *testing.T) {
+	// deployments that don't configure a mailer keep starting
+	assert.Equal(t, "file", Default().Notifications.Mailer)
+}
+
+func TestLoad_missing_file(t *testing.T) {
+	t.Setenv(FileEnv, filepath.Join(t.TempDir(), "missing.yaml"))
+
+	_, err := Load()
+	assert.ErrorContains(t, err, "could not open config file")
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name   string
+		modify func(cfg *Config)
+
+		expectedError string
+	}{
+		{
+			name:          "http addr missing",
+			modify:        func(cfg *Config) { cfg.HTTP.Addr = "" },
+			expectedError: "http.addr is required",
+		},
+		{
+			name:          "gateway addr missing",
+			modify:        func(cfg *Config) { cfg.GatewayAddr = "" },
+			expectedError: "gateway_addr is required",
+		},
+		{
+			name:          "redis addr missing",
+			modify:        func(cfg *Config) { cfg.Redis.Addr = "" },
+			expectedError: "redis.addr is required",
+		},
+		{
+			name:          "postgres url missing",
+			modify:        func(cfg *Config) { cfg.Postgres.URL = "" },
+			expectedError: "postgres.url is required",
+		},
+		{
+			name:          "consumer group prefix missing",
+			modify:        func(cfg *Config) { cfg.Broker.ConsumerGroupPrefix = "" },
+			expectedError: "broker.consumer_group_prefix is required",
+		},
+		{
+			name:          "tickets to print sheet missing",
+			modify:        func(cfg *Config) { cfg.Spreadsheets.TicketsToPrint = "" },
+			expectedError: "spreadsheets.tickets_to_print is required",
+		},
+		{
+			name:          "tickets to refund sheet missing",
+			modify:        func(cfg *Config) { cfg.Spreadsheets.TicketsToRefund = "" },
+			expectedError: "spreadsheets.tickets_to_refund is required",
+		},
+		{
+			name:          "tickets refunded sheet missing",
+			modify:        func(cfg *Config) { cfg.Spreadsheets.TicketsRefunded = "" },
+			expectedError: "spreadsheets.tickets_refunded is required",
+		},
+		{
+			name:          "bookings to release sheet missing",
+			modify:        func(cfg *Config) { cfg.Spreadsheets.BookingsToRelease = "" },
+			expectedError: "spreadsheets.bookings_to_release is required",
+		},
+		{
+			name:          "token secret missing",
+			modify:        func(cfg *Config) { cfg.Tickets.TokenSecret = "" },
+			expectedError: "tickets.token_secret is required",
+		},
+		{
+			name:          "notifications from missing",
+			modify:        func(cfg *Config) { cfg.Notifications.From = "" },
+			expectedError: "notifications.from is required",
+		},
+		{
+			name:          "idempotency key retention not positive",
+			modify:        func(cfg *Config) { cfg.HTTP.IdempotencyKeyRetention = 0 },
+			expectedError: "http.idempotency_key_retention must be positive",
+		},
+		{
+			name:          "retry initial interval not positive",
+			modify:        func(cfg *Config) { cfg.Broker.Retry.InitialInterval = 0 },
+			expectedError: "broker.retry.initial_interval must be positive",
+		},
+		{
+			name:          "retry max interval not positive",
+			modify:        func(cfg *Config) { cfg.Broker.Retry.MaxInterval = -time.Second },
+			expectedError: "broker.retry.max_interval must be positive",
+		},
+		{
+			name:          "processed events retention not positive",
+			modify:        func(cfg *Config) { cfg.Broker.ProcessedEventsRetention = 0 },
+			expectedError: "broker.processed_events_retention must be positive",
+		},
+		{
+			name:          "outbox poll interval not positive",
+			modify:        func(cfg *Config) { cfg.Outbox.PollInterval = 0 },
+			expectedError: "outbox.poll_interval must be positive",
+		},
+		{
+			name:          "outbox max lag not positive",
+			modify:        func(cfg *Config) { cfg.Outbox.MaxLag = 0 },
+			expectedError: "outbox.max_lag must be positive",
+		},
+		{
+			name:          "drain timeout not positive",
+			modify:        func(cfg *Config) { cfg.Shutdown.DrainTimeout = 0 },
+			expectedError: "shutdown.drain_timeout must be positive",
+		},
+		{
+			name:          "negative max retries",
+			modify:        func(cfg *Config) { cfg.Broker.Retry.MaxRetries = -1 },
+			expectedError: "broker.retry.max_retries can't be negative",
+		},
+		{
+			name:          "retry multiplier below 1",
+			modify:        func(cfg *Config) { cfg.Broker.Retry.Multiplier = 0.5 },
+			expectedError: "broker.retry.multiplier must be at least 1",
+		},
+		{
+			name:          "retry max interval below initial interval",
+			modify:        func(cfg *Config) { cfg.Broker.Retry.MaxInterval = cfg.Broker.Retry.InitialInterval / 2 },
+			expectedError: "broker.retry.max_interval can't be lower than initial_interval",
+		},
+		{
+			name:          "refund retry delay not positive",
+			modify:        func(cfg *Config) { cfg.Refunds.RetryDelay = 0 },
+			expectedError: "refunds.retry_delay must be positive",
+		},
+		{
+			name:          "refund max retry delay not positive",
+			modify:        func(cfg *Config) { cfg.Refunds.MaxRetryDelay = 0 },
+			expectedError: "refunds.max_retry_delay must be positive",
+		},
+		{
+			name:          "no refund attempts",
+			modify:        func(cfg *Config) { cfg.Refunds.MaxAttempts = 0 },
+			expectedError: "refunds.max_attempts must be at least 1",
+		},
+		{
+			name:          "refund max retry delay below retry delay",
+			modify:        func(cfg *Config) { cfg.Refunds.MaxRetryDelay = cfg.Refunds.RetryDelay / 2 },
+			expectedError: "refunds.max_retry_delay can't be lower than retry_delay",
+		},
+		{
+			name:          "file mailer without file",
+			modify:        func(cfg *Config) { cfg.Notifications.File = "" },
+			expectedError: "notifications.file is required by the file mailer",
+		},
+		{
+			name: "smtp mailer without addr",
+			modify: func(cfg *Config) {
+				cfg.Notifications.Mailer = "smtp"
+			},
+			expectedError: "notifications.smtp.addr is required by the smtp mailer",
+		},
+		{
+			name: "smtp mailer without timeout",
+			modify: func(cfg *Config) {
+				cfg.Notifications.Mailer = "smtp"
+				cfg.Notifications.SMTP.Addr = "smtp.example.com:587"
+				cfg.Notifications.SMTP.Timeout = 0
+			},
+			expectedError: "notifications.smtp.timeout must be positive",
+		},
+		{
+			name:          "mailer missing",
+			modify:        func(cfg *Config) { cfg.Notifications.Mailer = "" },
+			expectedError: "notifications.mailer is required",
+		},
+		{
+			name:          "unknown mailer",
+			modify:        func(cfg *Config) { cfg.Notifications.Mailer = "pigeon" },
+			expectedError: `unknown notifications.mailer "pigeon"`,
+		},
+		{
+			name:          "unknown tracing exporter",
+			modify:        func(cfg *Config) { cfg.Tracing.Exporter = "jaeger" },
+			expectedError: `unknown tracing.exporter "jaeger"`,
+		},
+	}
+
+	require.NoError(t, validConfig().Validate())
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := validConfig()
+			tc.modify(&cfg)
+
+			err := cfg.Validate()
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+// validConfig returns the defaults with the values that have no default set like Load's tests set them in env.
+func validConfig() Config {
+	cfg := Default()
+	cfg.GatewayAddr = "gateway:8080"
+	cfg.Redis.Addr = "redis:6379"
+	cfg.Postgres.URL = "postgres://postgres@postgres/db"
+	cfg.Tickets.TokenSecret = "secret"
+
+	return cfg
+}
+
+// unsetEnv unsets the env variable for the test, it's restored afterwards.
+func unsetEnv(t *testing.T, env string) {
+	t.Helper()
+
+	t.Setenv(env, "")
+	require.NoError(t, os.Unsetenv(env))
+}
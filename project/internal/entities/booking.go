@@ -6,12 +6,12 @@ import (
 
 type Booking struct {
 	BookingID uuid.UUID `json:"booking_id" db:"booking_id"`
-	ShowID    uuid.UUID `json:"show_id" db:"show_id"`
+	ShowID    uuid.UUID `json:"show_id" db:"show_id" validate:"required"`
 
-	NumberOfTickets int   `json:"number_of_tickets" db:"number_of_tickets"`
+	NumberOfTickets int   `json:"number_of_tickets" db:"number_of_tickets" validate:"gt=0"`
 	TicketIDs       UUIDs `json:"ticket_ids" db:"ticket_ids"`
 
-	CustomerEmail string `json:"customer_email" db:"customer_email"`
+	CustomerEmail string `json:"customer_email" db:"customer_email" validate:"required,email"`
 }
 
 type DeadNationBooking struct {
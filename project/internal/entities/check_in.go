@@ -0,0 +1,19 @@
+package entities
+
+import "time"
+
+type CheckInRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type CheckIn struct {
+	TicketID    string    `json:"ticket_id" db:"ticket_id"`
+	BookingID   string    `json:"booking_id,omitempty" db:"booking_id"`
+	ShowID      string    `json:"show_id,omitempty" db:"show_id"`
+	CheckedInAt time.Time `json:"checked_in_at" db:"checked_in_at"`
+}
+
+type ShowCheckIns struct {
+	ShowID    string `json:"show_id" db:"show_id"`
+	CheckedIn int    `json:"checked_in" db:"checked_in"`
+}
@@ -10,6 +10,11 @@ type RefundTicket struct {
 	TicketID string        `json:"ticket_id"`
 }
 
+type CancelBooking struct {
+	Header    CommandHeader `json:"header"`
+	BookingID uuid.UUID     `json:"booking_id"`
+}
+
 type CommandHeader struct {
 	ID             string    `json:"id"`
 	PublishedAt    time.Time `json:"published_at"`
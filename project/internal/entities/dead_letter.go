@@ -0,0 +1,20 @@
+package entities
+
+import (
+	"time"
+)
+
+type DeadLetter struct {
+	ID          string `json:"id"`
+	MessageUUID string `json:"message_uuid"`
+
+	Topic      string `json:"topic"`
+	Handler    string `json:"handler"`
+	Subscriber string `json:"subscriber"`
+	Reason     string `json:"reason"`
+
+	Payload  string            `json:"payload"`
+	Metadata map[string]string `json:"metadata"`
+
+	PoisonedAt time.Time `json:"poisoned_at"`
+}
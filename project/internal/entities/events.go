@@ -20,6 +20,15 @@ func NewEventHeader(idempotencyKey string) EventHeader {
 	}
 }
 
+// OccurredAt returns when the event was published, or now for events published without the time.
+func (h EventHeader) OccurredAt() time.Time {
+	if h.PublishedAt.IsZero() {
+		return time.Now().UTC()
+	}
+
+	return h.PublishedAt
+}
+
 type TicketBookingConfirmed struct {
 	Header EventHeader `json:"header,omitempty"`
 
@@ -51,6 +60,8 @@ type TicketBookingCanceled struct {
 	TicketID      string `json:"ticket_id"`
 	CustomerEmail string `json:"customer_email"`
 	Price         Money  `json:"price"`
+
+	BookingID string `json:"booking_id,omitempty"`
 }
 
 func (t *TicketBookingCanceled) ToSpreadsheetTicketPayload() []string {
@@ -82,3 +93,54 @@ type TicketReceiptIssued struct {
 
 	IssuedAt time.Time `json:"issued_at"`
 }
+
+type BookingCanceled struct {
+	Header EventHeader `json:"header"`
+
+	BookingID       uuid.UUID `json:"booking_id"`
+	ShowID          uuid.UUID `json:"show_id"`
+	NumberOfTickets int       `json:"number_of_tickets"`
+	CustomerEmail   string    `json:"customer_email"`
+}
+
+type TicketRefunded struct {
+	Header EventHeader `json:"header"`
+
+	TicketID string `json:"ticket_id"`
+}
+
+type TicketRefundFailed struct {
+	Header EventHeader `json:"header"`
+
+	TicketID string `json:"ticket_id"`
+	// Status is the last refund step that succeeded.
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+type ShowUpdated struct {
+	Header EventHeader `json:"header"`
+
+	ShowID          string    `json:"show_id"`
+	NumberOfTickets int       `json:"number_of_tickets"`
+	StartTime       time.Time `json:"start_time"`
+	Title           string    `json:"title"`
+	Venue           string    `json:"venue"`
+}
+
+type ShowCanceled struct {
+	Header EventHeader `json:"header"`
+
+	ShowID     string    `json:"show_id"`
+	CanceledAt time.Time `json:"canceled_at"`
+}
+
+type TicketCheckedIn struct {
+	Header EventHeader `json:"header"`
+
+	TicketID  string `json:"ticket_id"`
+	BookingID string `json:"booking_id,omitempty"`
+	// ShowID is empty for tickets that weren't booked through us.
+	ShowID      string    `json:"show_id,omitempty"`
+	CheckedInAt time.Time `json:"checked_in_at"`
+}
@@ -0,0 +1,13 @@
+package entities
+
+// IdempotentResponse is the response stored for an Idempotency-Key.
+// StatusCode is 0 while the first request with the key is still being handled.
+type IdempotentResponse struct {
+	RequestHash string
+	StatusCode  int
+	Body        []byte
+}
+
+func (r IdempotentResponse) InProgress() bool {
+	return r.StatusCode == 0
+}
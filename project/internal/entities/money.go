@@ -1,6 +1,7 @@
 package entities
 
 type Money struct {
-	Amount   string `json:"amount" db:"amount"`
+	Amount string `json:"amount" db:"amount"`
+	// Currency can be empty in gateway payloads, event handlers default it to USD.
 	Currency string `json:"currency" db:"currency"`
 }
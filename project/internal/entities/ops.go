@@ -5,6 +5,21 @@ import (
 	"time"
 )
 
+const (
+	OpsBookingsDefaultLimit = 50
+	OpsBookingsMaxLimit     = 200
+)
+
+type OpsBookingFilter struct {
+	Cursor string
+	Limit  int
+}
+
+type OpsBookingPage struct {
+	Items      []OpsBooking `json:"items"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
 type OpsBooking struct {
 	BookingID uuid.UUID `json:"booking_id"`
 	BookedAt  time.Time `json:"booked_at"`
@@ -0,0 +1,28 @@
+package entities
+
+import (
+	"time"
+)
+
+// Refund steps, in the order they are done.
+const (
+	RefundStatusStarted       = "started"
+	RefundStatusReceiptVoided = "receipt_voided"
+	RefundStatusCompleted     = "completed"
+)
+
+// Refund is the state of the refund process of a single ticket.
+// Status is the last step that succeeded, FailedAt is set when the refund needs manual intervention.
+type Refund struct {
+	TicketID       string `json:"ticket_id" db:"ticket_id"`
+	IdempotencyKey string `json:"idempotency_key" db:"idempotency_key"`
+
+	Status        string     `json:"status" db:"status"`
+	Attempts      int        `json:"attempts" db:"attempts"`
+	LastError     string     `json:"last_error" db:"last_error"`
+	FailedAt      *time.Time `json:"failed_at" db:"failed_at"`
+	NextAttemptAt *time.Time `json:"next_attempt_at" db:"next_attempt_at"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
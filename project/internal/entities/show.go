@@ -7,9 +7,26 @@ import (
 
 type Show struct {
 	ShowID         string    `json:"show_id" db:"show_id"`
-	DeadNationID   uuid.UUID `json:"dead_nation_id" db:"dead_nation_id"`
-	NumberOfTicket int       `json:"number_of_tickets" db:"number_of_tickets"`
-	StartTime      time.Time `json:"start_time" db:"start_time"`
-	Title          string    `json:"title" db:"title"`
-	Venue          string    `json:"venue" db:"venue"`
+	DeadNationID   uuid.UUID `json:"dead_nation_id" db:"dead_nation_id" validate:"required"`
+	NumberOfTicket int       `json:"number_of_tickets" db:"number_of_tickets" validate:"gt=0"`
+	StartTime      time.Time `json:"start_time" db:"start_time" validate:"required,future"`
+	Title          string    `json:"title" db:"title" validate:"required"`
+	Venue          string    `json:"venue" db:"venue" validate:"required"`
+
+	CanceledAt *time.Time `json:"canceled_at,omitempty" db:"canceled_at"`
+}
+
+// ShowUpdate holds the fields of PATCH /shows/:id, nil fields are left unchanged.
+type ShowUpdate struct {
+	NumberOfTickets *int       `json:"number_of_tickets" validate:"omitnil,gt=0"`
+	StartTime       *time.Time `json:"start_time" validate:"omitnil,future"`
+	Title           *string    `json:"title" validate:"omitnil,min=1"`
+	Venue           *string    `json:"venue" validate:"omitnil,min=1"`
+}
+
+type ShowAvailability struct {
+	ShowID         string `json:"show_id" db:"show_id"`
+	TotalSeats     int    `json:"total_seats" db:"total_seats"`
+	BookedSeats    int    `json:"booked_seats" db:"booked_seats"`
+	AvailableSeats int    `json:"available_seats" db:"available_seats"`
 }
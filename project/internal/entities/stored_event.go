@@ -0,0 +1,18 @@
+package entities
+
+import (
+	"time"
+)
+
+type StoredEvent struct {
+	Seq int64 `db:"seq"`
+
+	EventID   string `db:"event_id"`
+	EventName string `db:"event_name"`
+	Payload   []byte `db:"payload"`
+
+	PublishedAt    time.Time `db:"published_at"`
+	IdempotencyKey string    `db:"idempotency_key"`
+	CorrelationID  string    `db:"correlation_id"`
+	StoredAt       time.Time `db:"stored_at"`
+}
@@ -1,19 +1,29 @@
 package entities
 
+import "time"
+
 type Ticket struct {
 	Header        EventHeader `json:"header,omitempty"`
-	TicketID      string      `json:"ticket_id,omitempty"`
-	Status        string      `json:"status,omitempty"`
-	CustomerEmail string      `json:"customer_email,omitempty"`
+	TicketID      string      `json:"ticket_id,omitempty" validate:"required"`
+	Status        string      `json:"status,omitempty" validate:"oneof=confirmed canceled"`
+	CustomerEmail string      `json:"customer_email,omitempty" validate:"omitempty,email"`
 	Price         Money       `json:"price,omitempty"`
+	BookingID     string      `json:"booking_id,omitempty"`
 }
 
 type TicketList struct {
-	TicketID      string `json:"ticket_id,omitempty"`
-	CustomerEmail string `json:"customer_email,omitempty"`
-	Price         Money  `json:"price,omitempty"`
+	TicketID        string     `json:"ticket_id,omitempty"`
+	CustomerEmail   string     `json:"customer_email,omitempty"`
+	Price           Money      `json:"price,omitempty"`
+	Status          string     `json:"status,omitempty"`
+	BookingID       string     `json:"booking_id,omitempty"`
+	ConfirmedAt     *time.Time `json:"confirmed_at,omitempty"`
+	PrintedAt       *time.Time `json:"printed_at,omitempty"`
+	ReceiptIssuedAt *time.Time `json:"receipt_issued_at,omitempty"`
+	CanceledAt      *time.Time `json:"canceled_at,omitempty"`
+	RefundedAt      *time.Time `json:"refunded_at,omitempty"`
 }
 
 type TicketsStatusRequest struct {
-	Tickets []Ticket `json:"tickets"`
+	Tickets []Ticket `json:"tickets" validate:"required,dive"`
 }
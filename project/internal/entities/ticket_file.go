@@ -0,0 +1,14 @@
+package entities
+
+// TicketFile is a rendered ticket uploaded through the Files API.
+type TicketFile struct {
+	TicketID    string
+	Name        string
+	ContentType string
+	Content     []byte
+}
+
+type RenderedTicket struct {
+	HTML TicketFile
+	PDF  TicketFile
+}
@@ -0,0 +1,28 @@
+package entities
+
+const (
+	TicketsDefaultLimit = 50
+	TicketsMaxLimit     = 200
+)
+
+// TicketSortFields are the values accepted by TicketFilter.Sort, a leading "-" sorts descending.
+var TicketSortFields = []string{"confirmed_at", "price", "ticket_id"}
+
+type TicketFilter struct {
+	Status        string
+	CustomerEmail string
+	Currency      string
+	MinPrice      string
+	MaxPrice      string
+	ShowID        string
+	BookingID     string
+
+	Sort   string
+	Cursor string
+	Limit  int
+}
+
+type TicketPage struct {
+	Items      []TicketList `json:"items"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
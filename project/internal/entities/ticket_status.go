@@ -0,0 +1,25 @@
+package entities
+
+const (
+	TicketStatusConfirmed = "confirmed"
+	TicketStatusCanceled  = "canceled"
+	TicketStatusRefunded  = "refunded"
+)
+
+// ticketStatusTransitions lists the statuses a ticket can move to a status from.
+// Printing and issuing the receipt don't change the status, they are only recorded with timestamps.
+var ticketStatusTransitions = map[string][]string{
+	TicketStatusConfirmed: {},
+	TicketStatusCanceled:  {TicketStatusConfirmed},
+	TicketStatusRefunded:  {TicketStatusConfirmed, TicketStatusCanceled},
+}
+
+// TicketStatusesAllowedBefore returns the statuses from which a ticket can move to the given status.
+func TicketStatusesAllowedBefore(status string) []string {
+	return ticketStatusTransitions[status]
+}
+
+func IsValidTicketStatus(status string) bool {
+	_, ok := ticketStatusTransitions[status]
+	return ok
+}
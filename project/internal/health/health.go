@@ -0,0 +1,116 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
+	"sync"
+	"tickets/internal/broker/outbox"
+	"tickets/internal/entities"
+	"time"
+)
+
+const checkTimeout = 2 * time.Second
+
+type Check func(ctx context.Context) error
+
+// Checker runs readiness checks of the instance's dependencies.
+type Checker struct {
+	checks map[string]Check
+}
+
+func NewChecker(db *sqlx.DB, rdb *redis.Client, router *message.Router, maxOutboxLag time.Duration) *Checker {
+	if db == nil {
+		panic("missing db")
+	}
+	if rdb == nil {
+		panic("missing rdb")
+	}
+	if router == nil {
+		panic("missing router")
+	}
+	if maxOutboxLag <= 0 {
+		panic("maxOutboxLag must be positive")
+	}
+
+	return &Checker{
+		checks: map[string]Check{
+			"postgres": db.PingContext,
+			"redis": func(ctx context.Context) error {
+				return rdb.Ping(ctx).Err()
+			},
+			"router": func(ctx context.Context) error {
+				if router.IsClosed() {
+					return errors.New("router is closed")
+				}
+
+				select {
+				case <-router.Running():
+					return nil
+				default:
+					return errors.New("router is not running yet")
+				}
+			},
+			"outbox": func(ctx context.Context) error {
+				lag, err := outbox.Lag(ctx, db)
+				if err != nil {
+					return err
+				}
+				if lag > maxOutboxLag {
+					return fmt.Errorf("outbox lag %s is above %s", lag.Round(time.Millisecond), maxOutboxLag)
+				}
+				return nil
+			},
+		},
+	}
+}
+
+// Ready runs all checks concurrently, the instance is ready only if all of them pass.
+func (c *Checker) Ready(ctx context.Context) entities.HealthReport {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	report := entities.HealthReport{
+		Status: entities.HealthStatusOK,
+		Checks: make(map[string]entities.HealthCheck, len(c.checks)),
+	}
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+
+	for name, check := range c.checks {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+
+			start := time.Now()
+			err := check(ctx)
+
+			result := entities.HealthCheck{
+				Status:     entities.HealthStatusOK,
+				DurationMs: time.Since(start).Milliseconds(),
+			}
+			if err != nil {
+				result.Status = entities.HealthStatusFail
+				result.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Checks[name] = result
+			if err != nil {
+				report.Status = entities.HealthStatusFail
+			}
+		}(name, check)
+	}
+
+	wg.Wait()
+
+	return report
+}
@@ -3,17 +3,40 @@ package v1
 import (
 	commonHTTP "github.com/ThreeDotsLabs/go-event-driven/common/http"
 	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 func (h *Handler) SetRoutes() *echo.Echo {
 	router := commonHTTP.NewEcho()
+	router.Validator = newRequestValidator()
+	router.Use(traceRequests)
+	router.Use(h.metrics.HTTPMiddleware)
 
 	router.POST("/tickets-status", h.Tickets)
 	router.GET("/tickets", h.TicketsList)
+	router.POST("/tickets/:id/check-in", h.CheckInTicket)
 	router.GET("/health", h.Health)
-	router.POST("/shows", h.NewShow)
-	router.POST("/book-tickets", h.BookTicket)
+	router.GET("/health/live", h.Live)
+	router.GET("/health/ready", h.Ready)
+	router.GET("/metrics", echo.WrapHandler(promhttp.HandlerFor(h.metricsGatherer, promhttp.HandlerOpts{})))
+	router.POST("/shows", h.NewShow, h.idempotent)
+	router.GET("/shows", h.Shows)
+	router.GET("/shows/:id", h.ShowByID)
+	router.PATCH("/shows/:id", h.UpdateShow)
+	router.POST("/shows/:id/cancel", h.CancelShow)
+	router.GET("/shows/:id/availability", h.ShowAvailability)
+	router.GET("/shows/:id/check-ins", h.ShowCheckIns)
+	router.POST("/book-tickets", h.BookTicket, h.idempotent)
+	router.DELETE("/bookings/:id", h.CancelBooking)
 	router.PUT("/ticket-refund/:ticket_id", h.RefundTicket)
+	router.GET("/refunds", h.Refunds)
+	router.POST("/refunds/:ticket_id/retry", h.RetryRefund)
+	router.GET("/ops/bookings", h.OpsBookings)
+	router.GET("/ops/bookings/:id", h.OpsBookingByID)
+	router.GET("/dead-letters", h.DeadLetters)
+	router.GET("/dead-letters/:id", h.DeadLetterByID)
+	router.POST("/dead-letters/:id/requeue", h.RequeueDeadLetter)
+	router.DELETE("/dead-letters/:id", h.DeleteDeadLetter)
 
 	return router
 }
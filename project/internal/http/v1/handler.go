@@ -1,17 +1,40 @@
 package v1
 
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"tickets/internal/metrics"
+)
+
 type Handler struct {
-	eventPublisher   eventPublisher
 	commandPublisher commandSender
 	service          serviceI
 	watermillLogger  loggerI
+	readiness        readinessChecker
+	metrics          *metrics.Metrics
+	metricsGatherer  prometheus.Gatherer
 }
 
-func NewHandler(eventPublisher eventPublisher, commandPublisher commandSender, service serviceI, watermillLogger loggerI) *Handler {
+func NewHandler(
+	commandPublisher commandSender,
+	service serviceI,
+	watermillLogger loggerI,
+	readiness readinessChecker,
+	httpMetrics *metrics.Metrics,
+	metricsGatherer prometheus.Gatherer,
+) *Handler {
+	if httpMetrics == nil {
+		panic("missing httpMetrics")
+	}
+	if metricsGatherer == nil {
+		panic("missing metricsGatherer")
+	}
+
 	return &Handler{
-		eventPublisher:   eventPublisher,
 		commandPublisher: commandPublisher,
 		service:          service,
 		watermillLogger:  watermillLogger,
+		readiness:        readiness,
+		metrics:          httpMetrics,
+		metricsGatherer:  metricsGatherer,
 	}
 }
@@ -2,7 +2,9 @@ package v1
 
 import (
 	"errors"
+	"fmt"
 	"github.com/ThreeDotsLabs/watermill"
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"net/http"
 	"tickets/internal/entities"
@@ -12,12 +14,18 @@ import (
 func (h *Handler) BookTicket(c echo.Context) error {
 	var booking entities.Booking
 
-	if err := c.Bind(&booking); err != nil {
-		return c.String(http.StatusInternalServerError, err.Error())
+	if err := bindAndValidate(c, &booking); err != nil {
+		return err
 	}
 
 	bookingID, err := h.service.BookTicket(c.Request().Context(), booking)
 	if err != nil {
+		if errors.Is(err, booking2.ErrShowNotFound) {
+			return unprocessable(FieldError{Field: "show_id", Message: err.Error()})
+		}
+		if errors.Is(err, booking2.ErrShowCanceled) {
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		}
 		if errors.As(err, &booking2.NotEnoughSeatsAvailableError{}) {
 			h.watermillLogger.Error("", err, watermill.LogFields{"error": err.Error()})
 			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
@@ -30,3 +38,21 @@ func (h *Handler) BookTicket(c echo.Context) error {
 		"booking_id": bookingID,
 	})
 }
+
+func (h *Handler) CancelBooking(c echo.Context) error {
+	bookingID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid booking id")
+	}
+
+	command := entities.CancelBooking{
+		Header:    entities.NewCommandHeader(uuid.NewString()),
+		BookingID: bookingID,
+	}
+
+	if err := h.commandPublisher.Send(c.Request().Context(), command); err != nil {
+		return fmt.Errorf("failed to send CancelBooking command: %w", err)
+	}
+
+	return c.NoContent(http.StatusAccepted)
+}
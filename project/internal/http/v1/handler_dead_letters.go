@@ -0,0 +1,62 @@
+package v1
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+	"tickets/internal/broker/poison"
+)
+
+const defaultDeadLettersLimit = 100
+
+func (h *Handler) DeadLetters(c echo.Context) error {
+	limit := int64(defaultDeadLettersLimit)
+	if l := c.QueryParam("limit"); l != "" {
+		parsed, err := strconv.ParseInt(l, 10, 64)
+		if err != nil || parsed <= 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "limit must be a positive number")
+		}
+		limit = parsed
+	}
+
+	deadLetters, err := h.service.DeadLetters(c.Request().Context(), limit)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, deadLetters)
+}
+
+func (h *Handler) DeadLetterByID(c echo.Context) error {
+	deadLetter, err := h.service.DeadLetterByID(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return deadLetterError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, deadLetter)
+}
+
+func (h *Handler) RequeueDeadLetter(c echo.Context) error {
+	if err := h.service.RequeueDeadLetter(c.Request().Context(), c.Param("id")); err != nil {
+		return deadLetterError(c, err)
+	}
+
+	return c.NoContent(http.StatusAccepted)
+}
+
+func (h *Handler) DeleteDeadLetter(c echo.Context) error {
+	if err := h.service.DeleteDeadLetter(c.Request().Context(), c.Param("id")); err != nil {
+		return deadLetterError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func deadLetterError(c echo.Context, err error) error {
+	if errors.Is(err, poison.ErrDeadLetterNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+
+	return c.String(http.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,63 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+	"tickets/internal/entities"
+	"tickets/internal/repository/readModel"
+)
+
+func (h *Handler) OpsBookings(c echo.Context) error {
+	filter, err := opsBookingFilterFromQuery(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	page, err := h.service.ReservationList(c.Request().Context(), filter)
+	if err != nil {
+		if errors.Is(err, readModel.ErrInvalidCursor) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, page)
+}
+
+func (h *Handler) OpsBookingByID(c echo.Context) error {
+	bookingID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid booking id")
+	}
+
+	reservation, err := h.service.ReservationReadModel(c.Request().Context(), bookingID.String())
+	if err != nil {
+		if errors.Is(err, readModel.ErrReadModelNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, reservation)
+}
+
+func opsBookingFilterFromQuery(c echo.Context) (entities.OpsBookingFilter, error) {
+	filter := entities.OpsBookingFilter{
+		Cursor: c.QueryParam("cursor"),
+		Limit:  entities.OpsBookingsDefaultLimit,
+	}
+
+	if limit := c.QueryParam("limit"); limit != "" {
+		var err error
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil || filter.Limit < 1 || filter.Limit > entities.OpsBookingsMaxLimit {
+			return filter, fmt.Errorf("limit must be between 1 and %d", entities.OpsBookingsMaxLimit)
+		}
+	}
+
+	return filter, nil
+}
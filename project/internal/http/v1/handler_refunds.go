@@ -0,0 +1,57 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+	"tickets/internal/entities"
+	"tickets/internal/repository/refund"
+)
+
+// Refunds lists refunds, optionally filtered by status (the last step that succeeded)
+// and by failed=true for refunds waiting for manual intervention.
+func (h *Handler) Refunds(c echo.Context) error {
+	onlyFailed := false
+	if failed := c.QueryParam("failed"); failed != "" {
+		var err error
+		onlyFailed, err = strconv.ParseBool(failed)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "failed must be a boolean")
+		}
+	}
+
+	refunds, err := h.service.RefundList(c.Request().Context(), c.QueryParam("status"), onlyFailed)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, refunds)
+}
+
+// RetryRefund retries a failed refund from the step that failed.
+func (h *Handler) RetryRefund(c echo.Context) error {
+	r, err := h.service.ResetFailedRefund(c.Request().Context(), c.Param("ticket_id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, refund.ErrRefundNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case errors.Is(err, refund.ErrRefundNotFailed):
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		default:
+			return c.String(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	command := entities.RefundTicket{
+		Header:   entities.NewCommandHeader(r.IdempotencyKey),
+		TicketID: r.TicketID,
+	}
+
+	if err := h.commandPublisher.Send(c.Request().Context(), command); err != nil {
+		return fmt.Errorf("failed to send RefundTicket command: %w", err)
+	}
+
+	return c.NoContent(http.StatusAccepted)
+}
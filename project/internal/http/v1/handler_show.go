@@ -1,16 +1,20 @@
 package v1
 
 import (
+	"errors"
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"net/http"
 	"tickets/internal/entities"
+	"tickets/internal/repository/readModel"
+	"tickets/internal/repository/show"
 )
 
 func (h *Handler) NewShow(c echo.Context) error {
 	var show entities.Show
 
-	if err := c.Bind(&show); err != nil {
-		return c.String(http.StatusInternalServerError, err.Error())
+	if err := bindAndValidate(c, &show); err != nil {
+		return err
 	}
 
 	showID, err := h.service.NewShow(c.Request().Context(), show)
@@ -22,3 +26,106 @@ func (h *Handler) NewShow(c echo.Context) error {
 		"show_id": showID,
 	})
 }
+
+func (h *Handler) Shows(c echo.Context) error {
+	shows, err := h.service.ShowList(c.Request().Context())
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, shows)
+}
+
+func (h *Handler) ShowByID(c echo.Context) error {
+	showID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid show id")
+	}
+
+	s, err := h.service.ShowByID(c.Request().Context(), showID)
+	if err != nil {
+		if errors.Is(err, show.ErrShowNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, s)
+}
+
+func (h *Handler) UpdateShow(c echo.Context) error {
+	showID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid show id")
+	}
+
+	var update entities.ShowUpdate
+	if err := bindAndValidate(c, &update); err != nil {
+		return err
+	}
+
+	s, err := h.service.UpdateShow(c.Request().Context(), showID, update)
+	if err != nil {
+		switch {
+		case errors.Is(err, show.ErrShowNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case errors.Is(err, show.ErrShowCanceled), errors.As(err, &show.SeatsBelowBookedError{}):
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		default:
+			return c.String(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, s)
+}
+
+// CancelShow cancels the show, tickets booked on it are refunded asynchronously.
+func (h *Handler) CancelShow(c echo.Context) error {
+	showID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid show id")
+	}
+
+	if err := h.service.CancelShow(c.Request().Context(), showID); err != nil {
+		if errors.Is(err, show.ErrShowNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.NoContent(http.StatusAccepted)
+}
+
+func (h *Handler) ShowCheckIns(c echo.Context) error {
+	showID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid show id")
+	}
+
+	checkIns, err := h.service.ShowCheckIns(c.Request().Context(), showID)
+	if err != nil {
+		if errors.Is(err, readModel.ErrReadModelNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "show not found")
+		}
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, checkIns)
+}
+
+func (h *Handler) ShowAvailability(c echo.Context) error {
+	showID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid show id")
+	}
+
+	availability, err := h.service.ShowAvailability(c.Request().Context(), showID)
+	if err != nil {
+		if errors.Is(err, readModel.ErrReadModelNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "show not found")
+		}
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, availability)
+}
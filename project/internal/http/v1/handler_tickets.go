@@ -1,75 +1,81 @@
 package v1
 
 import (
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
-	"github.com/sirupsen/logrus"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 	"tickets/internal/entities"
+	"tickets/internal/repository/ticket"
+	"tickets/internal/tickettoken"
 )
 
+// Tickets records the status changes and their events in one transaction,
+// so the events are delivered by the outbox forwarder even if Redis is down.
 func (h *Handler) Tickets(c echo.Context) error {
 	var tickets entities.TicketsStatusRequest
-	if err := c.Bind(&tickets); err != nil {
+	if err := bindAndValidate(c, &tickets); err != nil {
 		return err
 	}
-	// iterate over tickets
-	for _, ticket := range tickets.Tickets {
-		// get idempotency key
-		idempotencyKey := c.Request().Header.Get("Idempotency-Key")
-		if idempotencyKey == "" {
-			return echo.NewHTTPError(http.StatusBadRequest, "Idempotency-Key header is required")
-		}
 
-		// add Header
-		ticket.Header = entities.NewEventHeader(idempotencyKey + ticket.TicketID)
-
-		switch ticket.Status {
-		case "confirmed":
-			// publish message
-			err := h.eventPublisher.Publish(c.Request().Context(), entities.TicketBookingConfirmed{
-				Header:        ticket.Header,
-				TicketID:      ticket.TicketID,
-				CustomerEmail: ticket.CustomerEmail,
-				Price:         ticket.Price,
-			})
-			if err != nil {
-				logrus.Error("send message to TicketBookingConfirmed topic: ", err)
-			}
-
-			err = h.eventPublisher.Publish(c.Request().Context(), entities.TicketPrinted{
-				Header:   ticket.Header,
-				TicketID: ticket.TicketID,
-				FileName: fmt.Sprintf("%s-ticket.html", ticket.TicketID),
-			})
-			if err != nil {
-				logrus.Error("send message to TicketPrinted topic: ", err)
-			}
-
-		case "canceled":
-			// publish message
-			err := h.eventPublisher.Publish(c.Request().Context(), entities.TicketBookingCanceled{
-				Header:        ticket.Header,
-				TicketID:      ticket.TicketID,
-				CustomerEmail: ticket.CustomerEmail,
-				Price:         ticket.Price,
-			})
-			if err != nil {
-				logrus.Error("send message to TicketBookingCanceled topic: ", err)
-			}
-		}
+	idempotencyKey := c.Request().Header.Get("Idempotency-Key")
+	if idempotencyKey == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "Idempotency-Key header is required")
+	}
+
+	if err := h.service.UpdateTicketStatuses(c.Request().Context(), idempotencyKey, tickets.Tickets); err != nil {
+		return fmt.Errorf("could not update ticket statuses: %w", err)
 	}
+
 	return c.NoContent(http.StatusOK)
 }
 
 func (h *Handler) TicketsList(c echo.Context) error {
-	tickets, err := h.service.TicketList(c.Request().Context())
+	filter, err := ticketFilterFromQuery(c)
 	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	page, err := h.service.TicketList(c.Request().Context(), filter)
+	if err != nil {
+		if errors.Is(err, ticket.ErrInvalidCursor) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
 		return c.String(http.StatusInternalServerError, err.Error())
 	}
 
-	return c.JSON(http.StatusOK, tickets)
+	return c.JSON(http.StatusOK, page)
+}
+
+// CheckInTicket verifies the token scanned from the printed ticket and lets the ticket in once.
+func (h *Handler) CheckInTicket(c echo.Context) error {
+	ticketID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid ticket id")
+	}
+
+	var req entities.CheckInRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	checkIn, err := h.service.CheckInTicket(c.Request().Context(), ticketID.String(), req.Token)
+	switch {
+	case errors.Is(err, tickettoken.ErrInvalidToken):
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+	case errors.Is(err, ticket.ErrTicketNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case errors.Is(err, ticket.ErrTicketAlreadyCheckedIn), errors.Is(err, ticket.ErrTicketNotConfirmed):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case err != nil:
+		return fmt.Errorf("could not check in ticket: %w", err)
+	}
+
+	return c.JSON(http.StatusOK, checkIn)
 }
 
 func (h *Handler) RefundTicket(c echo.Context) error {
@@ -90,3 +96,69 @@ func (h *Handler) RefundTicket(c echo.Context) error {
 func (h *Handler) Health(c echo.Context) error {
 	return c.String(http.StatusOK, "ok")
 }
+
+// Live reports that the process is up, it doesn't check any dependencies.
+func (h *Handler) Live(c echo.Context) error {
+	return c.JSON(http.StatusOK, entities.HealthReport{Status: entities.HealthStatusOK})
+}
+
+// Ready reports every dependency the instance needs to serve traffic, with 503 if any of them fails.
+func (h *Handler) Ready(c echo.Context) error {
+	report := h.readiness.Ready(c.Request().Context())
+	if report.Status != entities.HealthStatusOK {
+		return c.JSON(http.StatusServiceUnavailable, report)
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+func ticketFilterFromQuery(c echo.Context) (entities.TicketFilter, error) {
+	filter := entities.TicketFilter{
+		Status:        c.QueryParam("status"),
+		CustomerEmail: c.QueryParam("customer_email"),
+		Currency:      strings.ToUpper(c.QueryParam("currency")),
+		MinPrice:      c.QueryParam("min_price"),
+		MaxPrice:      c.QueryParam("max_price"),
+		ShowID:        c.QueryParam("show_id"),
+		BookingID:     c.QueryParam("booking_id"),
+		Sort:          c.QueryParam("sort"),
+		Cursor:        c.QueryParam("cursor"),
+		Limit:         entities.TicketsDefaultLimit,
+	}
+
+	if filter.Status != "" && !entities.IsValidTicketStatus(filter.Status) {
+		return filter, errors.New("invalid status")
+	}
+
+	for name, price := range map[string]string{"min_price": filter.MinPrice, "max_price": filter.MaxPrice} {
+		if price == "" {
+			continue
+		}
+		if _, err := strconv.ParseFloat(price, 64); err != nil {
+			return filter, fmt.Errorf("%s must be a number", name)
+		}
+	}
+
+	for name, id := range map[string]string{"show_id": filter.ShowID, "booking_id": filter.BookingID} {
+		if id == "" {
+			continue
+		}
+		if _, err := uuid.Parse(id); err != nil {
+			return filter, fmt.Errorf("invalid %s", name)
+		}
+	}
+
+	if filter.Sort != "" && !slices.Contains(entities.TicketSortFields, strings.TrimPrefix(filter.Sort, "-")) {
+		return filter, fmt.Errorf("sort must be one of %s, prefixed with - for descending order", strings.Join(entities.TicketSortFields, ", "))
+	}
+
+	if limit := c.QueryParam("limit"); limit != "" {
+		var err error
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil || filter.Limit < 1 || filter.Limit > entities.TicketsMaxLimit {
+			return filter, fmt.Errorf("limit must be between 1 and %d", entities.TicketsMaxLimit)
+		}
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,118 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+	"github.com/labstack/echo/v4"
+	"io"
+	"net/http"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// idempotent makes the endpoint safe to retry with the same Idempotency-Key header.
+// A repeated request with the same body gets the original response back, a repeated
+// key with a different body gets 409. Only successful responses are stored,
+// so a failed request can be retried with the same key.
+// The response is sent only once it's stored. If storing fails, the request fails and the key
+// stays in progress until it expires, as the handler may have already done its work.
+// Requests without the header are handled as usual.
+func (h *Handler) idempotent(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		key := c.Request().Header.Get(idempotencyKeyHeader)
+		if key == "" {
+			return next(c)
+		}
+
+		ctx := c.Request().Context()
+		endpoint := c.Request().Method + " " + c.Path()
+
+		body, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "could not read request body")
+		}
+		c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.Sum256(body)
+		requestHash := hex.EncodeToString(hash[:])
+
+		stored, reserved, err := h.service.ReserveIdempotencyKey(ctx, endpoint, key, requestHash)
+		if err != nil {
+			return err
+		}
+
+		if !reserved {
+			switch {
+			case stored.RequestHash != requestHash:
+				return echo.NewHTTPError(http.StatusConflict, "Idempotency-Key was already used with a different request body")
+			case stored.InProgress():
+				return echo.NewHTTPError(http.StatusConflict, "request with this Idempotency-Key is still being processed")
+			default:
+				return c.Blob(stored.StatusCode, echo.MIMEApplicationJSONCharsetUTF8, stored.Body)
+			}
+		}
+
+		writer := c.Response().Writer
+		recorder := &responseRecorder{ResponseWriter: writer}
+		c.Response().Writer = recorder
+
+		err = next(c)
+
+		c.Response().Writer = writer
+		status := c.Response().Status
+
+		if err == nil && c.Response().Committed && status >= 200 && status < 300 {
+			// stored even if the client has gone, so its retry gets the response
+			if saveErr := h.service.SaveIdempotentResponse(context.WithoutCancel(ctx), endpoint, key, status, recorder.body.Bytes()); saveErr != nil {
+				c.Response().Committed = false
+				return fmt.Errorf("could not store idempotent response: %w", saveErr)
+			}
+
+			return recorder.flush()
+		}
+
+		if recorder.wroteHeader {
+			if flushErr := recorder.flush(); flushErr != nil {
+				err = errors.Join(err, flushErr)
+			}
+		}
+
+		if releaseErr := h.service.ReleaseIdempotencyKey(ctx, endpoint, key); releaseErr != nil {
+			log.FromContext(ctx).WithError(releaseErr).Error("Failed to release idempotency key")
+		}
+
+		return err
+	}
+}
+
+// responseRecorder holds the response back until flush, so it can be stored first.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.wroteHeader = true
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
+
+// Flush does nothing, the response is sent by flush once it's stored.
+func (r *responseRecorder) Flush() {}
+
+// flush sends the recorded response.
+func (r *responseRecorder) flush() error {
+	r.ResponseWriter.WriteHeader(r.status)
+	_, err := r.ResponseWriter.Write(r.body.Bytes())
+	return err
+}
@@ -3,13 +3,10 @@ package v1
 import (
 	"context"
 	"github.com/ThreeDotsLabs/watermill"
+	"tickets/internal/entities"
 	"tickets/internal/service"
 )
 
-type eventPublisher interface {
-	Publish(ctx context.Context, event any) error
-}
-
 type commandSender interface {
 	Send(ctx context.Context, cmd any) error
 }
@@ -22,10 +19,18 @@ type loggerI interface {
 	With(fields watermill.LogFields) watermill.LoggerAdapter
 }
 
+type readinessChecker interface {
+	Ready(ctx context.Context) entities.HealthReport
+}
+
 type serviceI interface {
 	service.ReceiptsClient
 	service.SpreadsheetsClient
 	service.Ticket
 	service.Show
 	service.Booking
+	service.Ops
+	service.Refund
+	service.DeadLetterQueue
+	service.Idempotency
 }
@@ -0,0 +1,54 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"tickets/internal/tracing"
+)
+
+// traceRequests starts a span for every request, continuing the trace from the traceparent header.
+func traceRequests(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+
+		ctx := otel.GetTextMapPropagator().Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+		ctx, span := tracing.Tracer().Start(
+			ctx,
+			fmt.Sprintf("%s %s", req.Method, c.Path()),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", req.Method),
+				attribute.String("http.route", c.Path()),
+			),
+		)
+		defer span.End()
+
+		c.SetRequest(req.WithContext(ctx))
+
+		err := next(c)
+
+		status := c.Response().Status
+		if err != nil {
+			span.RecordError(err)
+			var httpErr *echo.HTTPError
+			if errors.As(err, &httpErr) {
+				status = httpErr.Code
+			} else {
+				status = http.StatusInternalServerError
+			}
+		}
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+
+		return err
+	}
+}
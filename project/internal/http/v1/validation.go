@@ -0,0 +1,126 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type ValidationError struct {
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"`
+}
+
+// requestValidator implements echo.Validator with the `validate` struct tags of the entities.
+type requestValidator struct {
+	validate *validator.Validate
+}
+
+func newRequestValidator() *requestValidator {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	// report fields by their json names, the same way clients send them
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	if err := v.RegisterValidation("future", func(fl validator.FieldLevel) bool {
+		t, ok := fl.Field().Interface().(time.Time)
+		return ok && t.After(time.Now())
+	}); err != nil {
+		panic(err)
+	}
+
+	return &requestValidator{validate: v}
+}
+
+func (v *requestValidator) Validate(i any) error {
+	return v.validate.Struct(i)
+}
+
+// bindAndValidate returns 400 when the body can't be parsed and 422 listing the failing fields
+// when it doesn't pass validation.
+func bindAndValidate(c echo.Context, req any) error {
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, ValidationError{Message: "invalid request body: " + bindErrorMessage(err)})
+	}
+
+	if err := c.Validate(req); err != nil {
+		var validationErrs validator.ValidationErrors
+		if !errors.As(err, &validationErrs) {
+			return err
+		}
+
+		return unprocessable(fieldErrors(validationErrs)...)
+	}
+
+	return nil
+}
+
+func unprocessable(fields ...FieldError) error {
+	return echo.NewHTTPError(http.StatusUnprocessableEntity, ValidationError{
+		Message: "validation failed",
+		Fields:  fields,
+	})
+}
+
+func fieldErrors(errs validator.ValidationErrors) []FieldError {
+	fields := make([]FieldError, 0, len(errs))
+	for _, e := range errs {
+		fields = append(fields, FieldError{
+			Field:   fieldPath(e.Namespace()),
+			Message: fieldMessage(e),
+		})
+	}
+
+	return fields
+}
+
+// fieldPath drops the struct name from the namespace, e.g. Booking.customer_email -> customer_email.
+func fieldPath(namespace string) string {
+	if _, path, ok := strings.Cut(namespace, "."); ok {
+		return path
+	}
+	return namespace
+}
+
+func fieldMessage(e validator.FieldError) string {
+	switch e.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email"
+	case "gt":
+		return "must be greater than " + e.Param()
+	case "min":
+		return "must be at least " + e.Param() + " characters long"
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(e.Param(), " ", ", ")
+	case "future":
+		return "must be in the future"
+	default:
+		return fmt.Sprintf("failed on %s validation", e.Tag())
+	}
+}
+
+func bindErrorMessage(err error) string {
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		return fmt.Sprint(httpErr.Message)
+	}
+	return err.Error()
+}
@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// HTTPMiddleware measures request latency per route.
+func (m *Metrics) HTTPMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+
+		err := next(c)
+
+		status := c.Response().Status
+		if err != nil {
+			var httpErr *echo.HTTPError
+			if errors.As(err, &httpErr) {
+				status = httpErr.Code
+			} else {
+				status = http.StatusInternalServerError
+			}
+		}
+
+		route := c.Path()
+		if route == "" {
+			route = "unknown"
+		}
+
+		m.httpRequestDuration.
+			WithLabelValues(c.Request().Method, route, strconv.Itoa(status)).
+			Observe(time.Since(start).Seconds())
+
+		return err
+	}
+}
@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "tickets"
+
+// Metrics holds the handler and HTTP collectors, registered in the registerer passed to NewMetrics.
+type Metrics struct {
+	messagesConsumed    *prometheus.CounterVec
+	messagesAcked       *prometheus.CounterVec
+	messagesNacked      *prometheus.CounterVec
+	handlerDuration     *prometheus.HistogramVec
+	messageRetries      *prometheus.CounterVec
+	httpRequestDuration *prometheus.HistogramVec
+}
+
+func NewMetrics(registerer prometheus.Registerer) *Metrics {
+	if registerer == nil {
+		panic("missing registerer")
+	}
+
+	factory := promauto.With(registerer)
+
+	return &Metrics{
+		messagesConsumed: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "messages_consumed_total",
+			Help:      "Messages received by a handler.",
+		}, []string{"handler"}),
+
+		messagesAcked: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "messages_acked_total",
+			Help:      "Messages processed successfully by a handler.",
+		}, []string{"handler"}),
+
+		messagesNacked: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "messages_nacked_total",
+			Help:      "Messages that failed in a handler after all retries.",
+		}, []string{"handler"}),
+
+		handlerDuration: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "handler_duration_seconds",
+			Help:      "Time spent handling a message, including retries.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"handler"}),
+
+		messageRetries: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "message_retries_total",
+			Help:      "Retries made by the retry middleware.",
+		}, []string{"handler"}),
+
+		httpRequestDuration: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+	}
+}
@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/ThreeDotsLabs/watermill/message/router/middleware"
+	"time"
+)
+
+// Middleware counts consumed, acked and nacked messages and measures handler latency.
+// It should be added before the retry middleware, so a message retried a few times is counted once.
+func (m *Metrics) Middleware(h message.HandlerFunc) message.HandlerFunc {
+	return func(msg *message.Message) ([]*message.Message, error) {
+		handlerName := message.HandlerNameFromCtx(msg.Context())
+		start := time.Now()
+
+		m.messagesConsumed.WithLabelValues(handlerName).Inc()
+
+		msgs, err := h(msg)
+
+		m.handlerDuration.WithLabelValues(handlerName).Observe(time.Since(start).Seconds())
+		if err != nil {
+			m.messagesNacked.WithLabelValues(handlerName).Inc()
+		} else {
+			m.messagesAcked.WithLabelValues(handlerName).Inc()
+		}
+
+		return msgs, err
+	}
+}
+
+// CountRetries returns the retry middleware with a hook counting retries per handler.
+func (m *Metrics) CountRetries(retry middleware.Retry) message.HandlerMiddleware {
+	return func(h message.HandlerFunc) message.HandlerFunc {
+		return func(msg *message.Message) ([]*message.Message, error) {
+			handlerName := message.HandlerNameFromCtx(msg.Context())
+
+			r := retry
+			r.OnRetryHook = func(retryNum int, delay time.Duration) {
+				m.messageRetries.WithLabelValues(handlerName).Inc()
+				if retry.OnRetryHook != nil {
+					retry.OnRetryHook(retryNum, delay)
+				}
+			}
+
+			return r.Middleware(h)(msg)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// FileMailer appends emails to a file as JSON lines instead of sending them, for local runs.
+type FileMailer struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileMailer(path string) *FileMailer {
+	if path == "" {
+		panic("missing path")
+	}
+
+	return &FileMailer{path: path}
+}
+
+func (m *FileMailer) Send(ctx context.Context, email Email) error {
+	line, err := json.Marshal(email)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("could not open emails file: %w", err)
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,29 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"tickets/internal/config"
+)
+
+type Email struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+type Mailer interface {
+	Send(ctx context.Context, email Email) error
+}
+
+// NewMailer creates the configured mailer.
+func NewMailer(cfg config.Notifications) (Mailer, error) {
+	switch cfg.Mailer {
+	case "file":
+		return NewFileMailer(cfg.File), nil
+	case "smtp":
+		return NewSMTPMailer(cfg.SMTP, cfg.From), nil
+	default:
+		return nil, fmt.Errorf("unknown mailer %q", cfg.Mailer)
+	}
+}
@@ -0,0 +1,34 @@
+package notifications
+
+import (
+	"context"
+	"sync"
+)
+
+// InMemoryMailer keeps sent emails in memory, for tests.
+type InMemoryMailer struct {
+	mu   sync.Mutex
+	sent []Email
+}
+
+func (m *InMemoryMailer) Send(ctx context.Context, email Email) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sent = append(m.sent, email)
+	return nil
+}
+
+// SentTo returns the emails sent to the address.
+func (m *InMemoryMailer) SentTo(to string) []Email {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var emails []Email
+	for _, email := range m.sent {
+		if email.To == to {
+			emails = append(emails, email)
+		}
+	}
+	return emails
+}
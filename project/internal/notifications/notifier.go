@@ -0,0 +1,149 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+	"github.com/ThreeDotsLabs/watermill/components/cqrs"
+	"github.com/google/uuid"
+	"strings"
+	"text/template"
+	"tickets/internal/entities"
+	"tickets/internal/render"
+	"time"
+)
+
+//go:embed templates
+var templates embed.FS
+
+type TicketService interface {
+	TicketByID(ctx context.Context, ticketID string) (entities.Ticket, error)
+}
+
+type ShowService interface {
+	ShowByID(ctx context.Context, showId uuid.UUID) (entities.Show, error)
+}
+
+// Notifier emails customers about their bookings, tickets, receipts and refunds.
+// Its handlers have to be deduplicated by event ID, so redelivered events don't send the email again.
+type Notifier struct {
+	mailer        Mailer
+	ticketService TicketService
+	showService   ShowService
+	templates     map[string]*template.Template
+}
+
+func NewNotifier(mailer Mailer, ticketService TicketService, showService ShowService) *Notifier {
+	if mailer == nil {
+		panic("missing mailer")
+	}
+	if ticketService == nil {
+		panic("missing ticketService")
+	}
+	if showService == nil {
+		panic("missing showService")
+	}
+
+	funcs := template.FuncMap{"formatTime": render.FormatTime}
+
+	parsed := make(map[string]*template.Template)
+	for _, name := range []string{"booking_made", "ticket_confirmed", "receipt_issued", "ticket_refunded"} {
+		parsed[name] = template.Must(template.New(name).Funcs(funcs).ParseFS(templates, "templates/"+name+".tmpl"))
+	}
+
+	return &Notifier{
+		mailer:        mailer,
+		ticketService: ticketService,
+		showService:   showService,
+		templates:     parsed,
+	}
+}
+
+// Handlers returns the notification handlers, each with its own consumer group.
+func (n *Notifier) Handlers() []cqrs.EventHandler {
+	return []cqrs.EventHandler{
+		cqrs.NewEventHandler("Notifications.OnBookingMade", n.OnBookingMade),
+		cqrs.NewEventHandler("Notifications.OnTicketBookingConfirmed", n.OnTicketBookingConfirmed),
+		cqrs.NewEventHandler("Notifications.OnTicketReceiptIssued", n.OnTicketReceiptIssued),
+		cqrs.NewEventHandler("Notifications.OnTicketRefunded", n.OnTicketRefunded),
+	}
+}
+
+func (n *Notifier) OnBookingMade(ctx context.Context, event *entities.BookingMade) error {
+	show, err := n.showService.ShowByID(ctx, event.ShowId)
+	if err != nil {
+		return fmt.Errorf("could not get show %s: %w", event.ShowId, err)
+	}
+
+	return n.send(ctx, event.CustomerEmail, "booking_made", struct {
+		BookingID       uuid.UUID
+		NumberOfTickets int
+		Show            *entities.Show
+	}{
+		BookingID:       event.BookingID,
+		NumberOfTickets: event.NumberOfTickets,
+		Show:            &show,
+	})
+}
+
+func (n *Notifier) OnTicketBookingConfirmed(ctx context.Context, event *entities.TicketBookingConfirmed) error {
+	return n.send(ctx, event.CustomerEmail, "ticket_confirmed", event)
+}
+
+func (n *Notifier) OnTicketReceiptIssued(ctx context.Context, event *entities.TicketReceiptIssued) error {
+	ticket, err := n.ticketService.TicketByID(ctx, event.TicketID)
+	if err != nil {
+		return fmt.Errorf("could not get ticket %s: %w", event.TicketID, err)
+	}
+
+	issuedAt := event.IssuedAt
+	if issuedAt.IsZero() {
+		issuedAt = event.Header.OccurredAt()
+	}
+
+	return n.send(ctx, ticket.CustomerEmail, "receipt_issued", struct {
+		TicketID      string
+		ReceiptNumber string
+		IssuedAt      time.Time
+	}{
+		TicketID:      event.TicketID,
+		ReceiptNumber: event.ReceiptNumber,
+		IssuedAt:      issuedAt,
+	})
+}
+
+func (n *Notifier) OnTicketRefunded(ctx context.Context, event *entities.TicketRefunded) error {
+	ticket, err := n.ticketService.TicketByID(ctx, event.TicketID)
+	if err != nil {
+		return fmt.Errorf("could not get ticket %s: %w", event.TicketID, err)
+	}
+
+	return n.send(ctx, ticket.CustomerEmail, "ticket_refunded", ticket)
+}
+
+// send skips emails without a recipient, customer email is optional in ticket status requests
+// and the SMTP server would reject the email on every retry.
+func (n *Notifier) send(ctx context.Context, to, templateName string, data any) error {
+	if to == "" {
+		log.FromContext(ctx).WithField("template", templateName).Info("Skipping email without a recipient")
+		return nil
+	}
+
+	tmpl := n.templates[templateName]
+
+	var subject, body bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return fmt.Errorf("could not render %s subject: %w", templateName, err)
+	}
+	if err := tmpl.ExecuteTemplate(&body, "body", data); err != nil {
+		return fmt.Errorf("could not render %s body: %w", templateName, err)
+	}
+
+	return n.mailer.Send(ctx, Email{
+		To:      to,
+		Subject: strings.TrimSpace(subject.String()),
+		Body:    strings.TrimSpace(body.String()),
+	})
+}
@@ -0,0 +1,131 @@
+package notifications
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"tickets/internal/config"
+	"time"
+)
+
+type SMTPMailer struct {
+	addr    string
+	host    string
+	from    string
+	auth    smtp.Auth
+	timeout time.Duration
+}
+
+func NewSMTPMailer(cfg config.SMTP, from string) *SMTPMailer {
+	if cfg.Addr == "" {
+		panic("missing smtp addr")
+	}
+	if from == "" {
+		panic("missing from")
+	}
+	if cfg.Timeout <= 0 {
+		panic("smtp timeout must be positive")
+	}
+
+	host, _, err := net.SplitHostPort(cfg.Addr)
+	if err != nil {
+		panic(fmt.Errorf("invalid smtp addr: %w", err))
+	}
+
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, host)
+	}
+
+	return &SMTPMailer{
+		addr:    cfg.Addr,
+		host:    host,
+		from:    from,
+		auth:    auth,
+		timeout: cfg.Timeout,
+	}
+}
+
+// Send sends the email within the timeout, or until ctx is done.
+func (m *SMTPMailer) Send(ctx context.Context, email Email) error {
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	if err := m.send(ctx, email); err != nil {
+		return fmt.Errorf("could not send email to %s: %w", email.To, err)
+	}
+
+	return nil
+}
+
+func (m *SMTPMailer) send(ctx context.Context, email Email) error {
+	dialer := net.Dialer{Timeout: m.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", m.addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// net/smtp doesn't take a context, the deadline and closing the connection stop a hung server
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+	}
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.Close()
+	})
+	defer stop()
+
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: m.host}); err != nil {
+			return err
+		}
+	}
+	if m.auth != nil {
+		if err := client.Auth(m.auth); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(m.from); err != nil {
+		return err
+	}
+	if err := client.Rcpt(email.To); err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte(m.message(email))); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+func (m *SMTPMailer) message(email Email) string {
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", m.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", email.To)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", email.Subject)
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(strings.ReplaceAll(email.Body, "\n", "\r\n"))
+	return msg.String()
+}
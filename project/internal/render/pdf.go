@@ -0,0 +1,43 @@
+package render
+
+import (
+	"bytes"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// writePDF lays out the rendered lines on an A6 page: the brand, the title, the details and the QR code.
+func writePDF(lines []string, qrCode []byte) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A6", "")
+	pdf.SetMargins(10, 10, 10)
+	pdf.AddPage()
+
+	// core fonts are cp1252, customer and show names may be in UTF-8
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	for i, line := range lines {
+		switch i {
+		case 0:
+			pdf.SetFont("Helvetica", "B", 10)
+			pdf.SetTextColor(192, 57, 43)
+			pdf.CellFormat(0, 6, tr(line), "", 1, "L", false, 0, "")
+		case 1:
+			pdf.SetFont("Helvetica", "B", 16)
+			pdf.SetTextColor(29, 29, 27)
+			pdf.MultiCell(0, 8, tr(line), "", "L", false)
+			pdf.Ln(2)
+		default:
+			pdf.SetFont("Helvetica", "", 10)
+			pdf.MultiCell(0, 5, tr(line), "", "L", false)
+		}
+	}
+
+	pdf.RegisterImageOptionsReader("qr", gofpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(qrCode))
+	pdf.ImageOptions("qr", 29, pdf.GetY()+4, 47, 47, false, gofpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+
+	var out bytes.Buffer
+	if err := pdf.Output(&out); err != nil {
+		return nil, err
+	}
+
+	return out.Bytes(), nil
+}
@@ -0,0 +1,111 @@
+package render
+
+import (
+	"bytes"
+	"embed"
+	"encoding/base64"
+	"fmt"
+	"github.com/skip2/go-qrcode"
+	htmlTemplate "html/template"
+	"strings"
+	textTemplate "text/template"
+	"tickets/internal/entities"
+	"time"
+)
+
+const (
+	brand = "Tickets"
+
+	qrCodeSize = 256
+)
+
+//go:embed templates
+var templates embed.FS
+
+type tokenSigner interface {
+	Sign(ticketID string) string
+}
+
+// Renderer renders tickets from the templates in templates/, as HTML and PDF files
+// with a QR code of the signed ticket token.
+type Renderer struct {
+	signer tokenSigner
+	html   *htmlTemplate.Template
+	pdf    *textTemplate.Template
+}
+
+func NewRenderer(signer tokenSigner) *Renderer {
+	if signer == nil {
+		panic("missing signer")
+	}
+
+	funcs := map[string]any{"formatTime": FormatTime}
+
+	return &Renderer{
+		signer: signer,
+		html:   htmlTemplate.Must(htmlTemplate.New("ticket.html.tmpl").Funcs(funcs).ParseFS(templates, "templates/ticket.html.tmpl")),
+		pdf:    textTemplate.Must(textTemplate.New("ticket.pdf.tmpl").Funcs(funcs).ParseFS(templates, "templates/ticket.pdf.tmpl")),
+	}
+}
+
+type ticketData struct {
+	Brand         string
+	TicketID      string
+	CustomerEmail string
+	Price         entities.Money
+	// Show is nil for tickets that weren't booked through us.
+	Show   *entities.Show
+	QRCode htmlTemplate.URL
+}
+
+// RenderTicket renders the ticket, show is optional.
+func (r *Renderer) RenderTicket(ticket entities.TicketBookingConfirmed, show *entities.Show) (entities.RenderedTicket, error) {
+	qrCode, err := qrcode.Encode(r.signer.Sign(ticket.TicketID), qrcode.Medium, qrCodeSize)
+	if err != nil {
+		return entities.RenderedTicket{}, fmt.Errorf("could not encode QR code: %w", err)
+	}
+
+	data := ticketData{
+		Brand:         brand,
+		TicketID:      ticket.TicketID,
+		CustomerEmail: ticket.CustomerEmail,
+		Price:         ticket.Price,
+		Show:          show,
+		QRCode:        htmlTemplate.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(qrCode)),
+	}
+
+	var html bytes.Buffer
+	if err := r.html.Execute(&html, data); err != nil {
+		return entities.RenderedTicket{}, fmt.Errorf("could not render html ticket: %w", err)
+	}
+
+	var lines bytes.Buffer
+	if err := r.pdf.Execute(&lines, data); err != nil {
+		return entities.RenderedTicket{}, fmt.Errorf("could not render pdf ticket: %w", err)
+	}
+
+	pdf, err := writePDF(strings.Split(strings.TrimSpace(lines.String()), "\n"), qrCode)
+	if err != nil {
+		return entities.RenderedTicket{}, fmt.Errorf("could not render pdf ticket: %w", err)
+	}
+
+	return entities.RenderedTicket{
+		HTML: entities.TicketFile{
+			TicketID:    ticket.TicketID,
+			Name:        fmt.Sprintf("%s-ticket.html", ticket.TicketID),
+			ContentType: "text/html",
+			Content:     html.Bytes(),
+		},
+		PDF: entities.TicketFile{
+			TicketID:    ticket.TicketID,
+			Name:        fmt.Sprintf("%s-ticket.pdf", ticket.TicketID),
+			ContentType: "application/pdf",
+			Content:     pdf,
+		},
+	}, nil
+}
+
+// FormatTime formats times shown to customers, on tickets and in emails.
+func FormatTime(t time.Time) string {
+	return t.UTC().Format("Mon, 02 Jan 2006 15:04 MST")
+}
@@ -0,0 +1,27 @@
+package replay
+
+import (
+	"github.com/ThreeDotsLabs/watermill/components/cqrs"
+	"github.com/jmoiron/sqlx"
+	"tickets/internal/broker/event"
+	"tickets/internal/repository/readModel"
+)
+
+// Projections returns all projections that can be rebuilt, by name.
+func Projections(db *sqlx.DB) map[string]Projection {
+	return map[string]Projection{
+		"ops-bookings": opsBookingsProjection{readModel: readModel.NewOpsBookingReadModel(db)},
+	}
+}
+
+type opsBookingsProjection struct {
+	readModel readModel.OpsBookingReadModel
+}
+
+func (p opsBookingsProjection) Table() string {
+	return readModel.OpsBookingsTable
+}
+
+func (p opsBookingsProjection) EventHandlers(table string) []cqrs.EventHandler {
+	return event.OpsReadModelHandlers(p.readModel.WithTable(table))
+}
@@ -0,0 +1,210 @@
+package replay
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+	"github.com/ThreeDotsLabs/watermill/components/cqrs"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+	"tickets/internal/entities"
+	"tickets/internal/repository/transaction"
+	"time"
+)
+
+// catchUpOverlap is how long before the replay started the catch-up looks for events again.
+// An event's stored_at and seq are assigned when its insert starts, but it's visible only once it commits,
+// so an insert running while the history is read can commit with a lower seq than the events already replayed.
+const catchUpOverlap = time.Minute
+
+// Projection is a read model that can be rebuilt from the events store.
+type Projection interface {
+	// Table is the table the projection is served from.
+	Table() string
+	// EventHandlers returns handlers projecting events into the given table.
+	EventHandlers(table string) []cqrs.EventHandler
+}
+
+type EventsStore interface {
+	EventsAfter(ctx context.Context, afterSeq int64, fn func(event entities.StoredEvent) error) (int64, error)
+	EventsStoredSince(ctx context.Context, since time.Time, fn func(event entities.StoredEvent) error) error
+}
+
+type Replayer struct {
+	db          *sqlx.DB
+	eventsStore EventsStore
+}
+
+func NewReplayer(db *sqlx.DB, eventsStore EventsStore) *Replayer {
+	if db == nil {
+		panic("missing db")
+	}
+	if eventsStore == nil {
+		panic("missing eventsStore")
+	}
+
+	return &Replayer{db: db, eventsStore: eventsStore}
+}
+
+// Rebuild streams the whole events history through the projection into a shadow table
+// and then swaps the shadow table with the live one in a single transaction.
+//
+// Events that were published, but not yet stored in the events store when the swap happens
+// are not projected, so it's best to run it when the events store handler has no lag.
+func (r *Replayer) Rebuild(ctx context.Context, projection Projection) error {
+	table := projection.Table()
+	shadowTable := table + "_rebuild"
+
+	logger := log.FromContext(ctx).WithFields(logrus.Fields{
+		"table":        table,
+		"shadow_table": shadowTable,
+	})
+
+	_, err := r.db.ExecContext(ctx, fmt.Sprintf(
+		"DROP TABLE IF EXISTS %[1]s; CREATE TABLE %[1]s (LIKE %[2]s INCLUDING ALL)",
+		pq.QuoteIdentifier(shadowTable),
+		pq.QuoteIdentifier(table),
+	))
+	if err != nil {
+		return fmt.Errorf("could not create shadow table %s: %w", shadowTable, err)
+	}
+
+	// the database clock, as stored_at is set by it
+	var replayStartedAt time.Time
+	if err := r.db.GetContext(ctx, &replayStartedAt, "SELECT localtimestamp"); err != nil {
+		return fmt.Errorf("could not get replay start time: %w", err)
+	}
+	catchUpSince := replayStartedAt.Add(-catchUpOverlap)
+
+	p := newProjector(projection.EventHandlers(shadowTable))
+
+	// events the catch-up returns again, so they are not projected twice
+	replayed := map[string]struct{}{}
+
+	lastSeq, err := r.eventsStore.EventsAfter(ctx, 0, func(event entities.StoredEvent) error {
+		if !event.StoredAt.Before(catchUpSince) {
+			replayed[event.EventID] = struct{}{}
+		}
+
+		p.project(ctx, event)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("could not replay events: %w", err)
+	}
+
+	logger.WithField("last_seq", lastSeq).Info("Events replayed, swapping tables")
+
+	return transaction.UpdateInTx(
+		ctx,
+		r.db,
+		sql.LevelReadCommitted,
+		func(ctx context.Context, tx *sqlx.Tx) error {
+			// live handlers are blocked until the swap is done, so they don't write to the table being replaced.
+			// The events store is filled asynchronously though: an event already applied by a live handler,
+			// but not stored yet, is missing from the rebuilt table.
+			_, err := tx.ExecContext(ctx, fmt.Sprintf("LOCK TABLE %s IN ACCESS EXCLUSIVE MODE", pq.QuoteIdentifier(table)))
+			if err != nil {
+				return fmt.Errorf("could not lock %s: %w", table, err)
+			}
+
+			err = r.eventsStore.EventsStoredSince(ctx, catchUpSince, func(event entities.StoredEvent) error {
+				if _, ok := replayed[event.EventID]; ok {
+					return nil
+				}
+
+				p.project(ctx, event)
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("could not catch up events: %w", err)
+			}
+
+			if err := p.retryPending(ctx); err != nil {
+				return err
+			}
+
+			_, err = tx.ExecContext(ctx, fmt.Sprintf(
+				"ALTER TABLE %[1]s RENAME TO %[2]s; ALTER TABLE %[3]s RENAME TO %[1]s; DROP TABLE %[2]s",
+				pq.QuoteIdentifier(table),
+				pq.QuoteIdentifier(table+"_old"),
+				pq.QuoteIdentifier(shadowTable),
+			))
+			if err != nil {
+				return fmt.Errorf("could not swap %s with %s: %w", table, shadowTable, err)
+			}
+
+			return nil
+		},
+	)
+}
+
+type pendingEvent struct {
+	event   entities.StoredEvent
+	handler cqrs.EventHandler
+	err     error
+}
+
+type projector struct {
+	handlers map[string][]cqrs.EventHandler
+	pending  []pendingEvent
+}
+
+func newProjector(handlers []cqrs.EventHandler) *projector {
+	p := &projector{handlers: map[string][]cqrs.EventHandler{}}
+	for _, h := range handlers {
+		eventName := cqrs.StructName(h.NewEvent())
+		p.handlers[eventName] = append(p.handlers[eventName], h)
+	}
+
+	return p
+}
+
+// project passes the event to each handler interested in it.
+// Failed events are kept, as they may depend on events that are stored later (like the live handlers do,
+// the read model may refuse to update a booking that doesn't exist yet).
+func (p *projector) project(ctx context.Context, event entities.StoredEvent) {
+	for _, h := range p.handlers[event.EventName] {
+		if err := handle(ctx, h, event); err != nil {
+			p.pending = append(p.pending, pendingEvent{event: event, handler: h, err: err})
+		}
+	}
+}
+
+// retryPending retries failed events for as long as any of them succeeds.
+func (p *projector) retryPending(ctx context.Context) error {
+	for len(p.pending) > 0 {
+		var stillPending []pendingEvent
+		for _, pe := range p.pending {
+			if err := handle(ctx, pe.handler, pe.event); err != nil {
+				pe.err = err
+				stillPending = append(stillPending, pe)
+			}
+		}
+
+		if len(stillPending) == len(p.pending) {
+			errs := make([]error, 0, len(stillPending))
+			for _, pe := range stillPending {
+				errs = append(errs, fmt.Errorf("event %s (%s): %w", pe.event.EventID, pe.event.EventName, pe.err))
+			}
+			return fmt.Errorf("could not project %d events: %w", len(stillPending), errors.Join(errs...))
+		}
+
+		p.pending = stillPending
+	}
+
+	return nil
+}
+
+func handle(ctx context.Context, h cqrs.EventHandler, event entities.StoredEvent) error {
+	ev := h.NewEvent()
+	if err := json.Unmarshal(event.Payload, ev); err != nil {
+		return fmt.Errorf("could not unmarshal event: %w", err)
+	}
+
+	return h.Handle(ctx, ev)
+}
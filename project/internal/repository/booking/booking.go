@@ -3,12 +3,14 @@ package booking
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/ThreeDotsLabs/watermill"
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
-	"tickets/internal/broker/event"
 	"tickets/internal/broker/outbox"
 	"tickets/internal/entities"
+	"tickets/internal/repository/transaction"
 )
 
 type Repo struct {
@@ -23,6 +25,12 @@ func NewRepo(db *sqlx.DB) *Repo {
 	}
 }
 
+var (
+	ErrShowNotFound    = errors.New("show not found")
+	ErrBookingNotFound = errors.New("booking not found")
+	ErrShowCanceled    = errors.New("show is canceled")
+)
+
 type NotEnoughSeatsAvailableError struct {
 	Available int
 	Booked    int
@@ -32,76 +40,97 @@ func (e NotEnoughSeatsAvailableError) Error() string {
 	return fmt.Sprintf("not enough seats available, available: %d, booked: %d", e.Available, e.Booked)
 }
 
+// BookTicket books tickets if the show has enough seats left.
+// Seats are checked in the same transaction as the booking is inserted, with the show row locked,
+// so concurrent bookings of the show wait for each other and can't overbook it.
+// It runs in read committed, so the seat count is read after the lock is granted
+// and sees the bookings committed by the transactions that held it before.
+// The show lock is the only one taken, so there are no serialization failures or deadlocks to retry.
 func (r *Repo) BookTicket(ctx context.Context, booking entities.Booking) (string, error) {
-	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{
-		Isolation: sql.LevelSerializable,
-	})
-	if err != nil {
-		return "", err
-	}
-
-	row := r.db.QueryRowContext(ctx, compareBeforeBooking, booking.ShowID)
-	if err != nil {
-		return "", err
-	}
-	var available, booked int
-
-	err = row.Scan(&available, &booked)
-	if err != nil {
-		return "", err
-	}
-
-	if (available - booked) < booking.NumberOfTickets {
-		tx.Rollback()
-		return "", NotEnoughSeatsAvailableError{
-			available,
-			booked,
-		}
-	}
-
-	rows, err := tx.QueryContext(ctx, inserBooking, booking.BookingID, booking.ShowID, booking.NumberOfTickets, booking.CustomerEmail)
-	if err != nil {
-		rbErr := tx.Rollback()
-		if rbErr != nil {
-			return "", err
-		}
-
-		return "", err
-	}
-	defer rows.Close()
-
 	var bookingID string
-	for rows.Next() {
-		if err := rows.Scan(&bookingID); err != nil {
-			return "", err
-		}
-	}
-
-	outboxPublisher, err := outbox.NewPublisherForDb(ctx, tx)
-	if err != nil {
-		return "", fmt.Errorf("could not create event bus: %w", err)
-	}
-
-	eventBus, err := event.NewEventBus(outboxPublisher)
-	if err != nil {
-		return "", fmt.Errorf("could not create event bus for book ticket: %w", err)
-	}
 
-	err = eventBus.Publish(ctx, entities.BookingMade{
-		Header:          entities.NewEventHeader(""),
-		BookingID:       booking.BookingID,
-		NumberOfTickets: booking.NumberOfTickets,
-		CustomerEmail:   booking.CustomerEmail,
-		ShowId:          booking.ShowID,
-	})
+	err := transaction.UpdateInTx(
+		ctx,
+		r.db,
+		sql.LevelReadCommitted,
+		func(ctx context.Context, tx *sqlx.Tx) error {
+			var showCanceled bool
+			if err := tx.GetContext(ctx, &showCanceled, lockShow, booking.ShowID); err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return ErrShowNotFound
+				}
+				return fmt.Errorf("could not lock show: %w", err)
+			}
+			if showCanceled {
+				return ErrShowCanceled
+			}
+
+			var available, booked int
+			if err := tx.QueryRowContext(ctx, compareBeforeBooking, booking.ShowID).Scan(&available, &booked); err != nil {
+				return fmt.Errorf("could not count booked tickets: %w", err)
+			}
+
+			if (available - booked) < booking.NumberOfTickets {
+				return NotEnoughSeatsAvailableError{
+					available,
+					booked,
+				}
+			}
+
+			err := tx.GetContext(ctx, &bookingID, inserBooking, booking.BookingID, booking.ShowID, booking.NumberOfTickets, booking.CustomerEmail)
+			if err != nil {
+				return fmt.Errorf("could not insert booking: %w", err)
+			}
+
+			return outbox.PublishInTx(ctx, tx.Tx, entities.BookingMade{
+				Header:          entities.NewEventHeader(""),
+				BookingID:       booking.BookingID,
+				NumberOfTickets: booking.NumberOfTickets,
+				CustomerEmail:   booking.CustomerEmail,
+				ShowId:          booking.ShowID,
+			})
+		},
+	)
 	if err != nil {
 		return "", err
 	}
 
-	err = tx.Commit()
-	if err != nil {
-		return "", err
-	}
+	return bookingID, nil
+}
 
-	return bookingID, err
+// CancelBooking marks the booking as canceled, so its seats are available again,
+// and publishes BookingCanceled in the same transaction.
+// Canceling an already canceled booking does nothing.
+func (r *Repo) CancelBooking(ctx context.Context, bookingID uuid.UUID) error {
+	return transaction.UpdateInTxWithRetry(
+		ctx,
+		r.db,
+		sql.LevelReadCommitted,
+		func(ctx context.Context, tx *sqlx.Tx) error {
+			var booking entities.Booking
+			err := tx.GetContext(ctx, &booking, cancelBooking, bookingID)
+			if errors.Is(err, sql.ErrNoRows) {
+				var exists bool
+				if err := tx.GetContext(ctx, &exists, bookingExists, bookingID); err != nil {
+					return fmt.Errorf("could not check if booking exists: %w", err)
+				}
+				if !exists {
+					return ErrBookingNotFound
+				}
+
+				// already canceled
+				return nil
+			} else if err != nil {
+				return fmt.Errorf("could not cancel booking: %w", err)
+			}
+
+			return outbox.PublishInTx(ctx, tx.Tx, entities.BookingCanceled{
+				Header:          entities.NewEventHeader(bookingID.String()),
+				BookingID:       booking.BookingID,
+				ShowID:          booking.ShowID,
+				NumberOfTickets: booking.NumberOfTickets,
+				CustomerEmail:   booking.CustomerEmail,
+			})
+		},
+	)
 }
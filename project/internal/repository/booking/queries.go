@@ -5,6 +5,25 @@ const (
 INSERT INTO bookings (booking_id, show_id, number_of_tickets, customer_email)
 VALUES ($1, $2, $3, $4)
 RETURNING booking_id
+`
+
+	cancelBooking = `
+UPDATE bookings
+SET canceled_at = now()
+WHERE booking_id = $1 AND canceled_at IS NULL
+RETURNING booking_id, show_id, number_of_tickets, customer_email
+`
+
+	bookingExists = `
+SELECT EXISTS (SELECT 1 FROM bookings WHERE booking_id = $1)
+`
+
+	// lockShow locks the show row, so concurrent bookings of the same show are checked one by one.
+	lockShow = `
+SELECT canceled_at IS NOT NULL
+FROM shows
+WHERE show_id = $1
+FOR UPDATE
 `
 
 	compareBeforeBooking = `
@@ -14,7 +33,7 @@ SELECT
 FROM
   shows s
 LEFT JOIN
-  bookings b ON s.show_id = b.show_id
+  bookings b ON s.show_id = b.show_id AND b.canceled_at IS NULL
 WHERE
   s.show_id = $1
 GROUP BY
@@ -4,12 +4,11 @@ import (
 	"context"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
-	"os"
 	"time"
 )
 
-func InitDB() (*sqlx.DB, error) {
-	db, err := sqlx.Open("postgres", os.Getenv("POSTGRES_URL"))
+func InitDB(url string) (*sqlx.DB, error) {
+	db, err := sqlx.Open("postgres", url)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,99 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"tickets/internal/entities"
+	"time"
+)
+
+type Repo struct {
+	db *sqlx.DB
+}
+
+func NewRepo(db *sqlx.DB) *Repo {
+	return &Repo{
+		db: db,
+	}
+}
+
+func (r *Repo) AppendEvent(ctx context.Context, event entities.StoredEvent) error {
+	_, err := r.db.ExecContext(ctx, appendEvent,
+		event.EventID,
+		event.EventName,
+		event.Payload,
+		event.PublishedAt,
+		event.IdempotencyKey,
+		event.CorrelationID,
+	)
+	if err != nil {
+		return fmt.Errorf("could not append event %s: %w", event.EventID, err)
+	}
+
+	return nil
+}
+
+func (r *Repo) GetByID(ctx context.Context, eventID string) (entities.StoredEvent, error) {
+	var event entities.StoredEvent
+	err := r.db.GetContext(ctx, &event, getEventByID, eventID)
+
+	return event, err
+}
+
+// EventsAfter streams stored events with seq greater than afterSeq in the order they were stored.
+// It returns the seq of the last event passed to fn.
+func (r *Repo) EventsAfter(
+	ctx context.Context,
+	afterSeq int64,
+	fn func(event entities.StoredEvent) error,
+) (int64, error) {
+	rows, err := r.db.QueryxContext(ctx, eventsAfter, afterSeq)
+	if err != nil {
+		return afterSeq, err
+	}
+	defer rows.Close()
+
+	lastSeq := afterSeq
+	for rows.Next() {
+		var event entities.StoredEvent
+		if err := rows.StructScan(&event); err != nil {
+			return lastSeq, err
+		}
+
+		if err := fn(event); err != nil {
+			return lastSeq, err
+		}
+
+		lastSeq = event.Seq
+	}
+
+	return lastSeq, rows.Err()
+}
+
+// EventsStoredSince streams events stored at or after since in seq order.
+// Unlike EventsAfter, it returns events that got a lower seq, but were committed after a later one.
+func (r *Repo) EventsStoredSince(
+	ctx context.Context,
+	since time.Time,
+	fn func(event entities.StoredEvent) error,
+) error {
+	rows, err := r.db.QueryxContext(ctx, eventsStoredSince, since)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var event entities.StoredEvent
+		if err := rows.StructScan(&event); err != nil {
+			return err
+		}
+
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
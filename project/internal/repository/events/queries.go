@@ -0,0 +1,32 @@
+package events
+
+const (
+	appendEvent = `
+INSERT INTO events (
+  event_id, event_name, payload, published_at, idempotency_key, correlation_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (event_id) DO NOTHING
+`
+
+	eventsAfter = `
+SELECT seq, event_id, event_name, payload, published_at, idempotency_key, correlation_id, stored_at
+FROM events
+WHERE seq > $1
+ORDER BY seq
+`
+
+	eventsStoredSince = `
+SELECT seq, event_id, event_name, payload, published_at, idempotency_key, correlation_id, stored_at
+FROM events
+WHERE stored_at >= $1
+ORDER BY seq
+`
+
+	getEventByID = `
+SELECT seq, event_id, event_name, payload, published_at, idempotency_key, correlation_id, stored_at
+FROM events
+WHERE event_id = $1
+`
+)
@@ -0,0 +1,66 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"tickets/internal/entities"
+	"time"
+)
+
+type Repo struct {
+	db *sqlx.DB
+}
+
+func NewRepo(db *sqlx.DB) *Repo {
+	return &Repo{db: db}
+}
+
+// ReserveKey reserves the key for a new request and returns true.
+// If the key is already used, it returns false with what was stored for it.
+// A key stays in progress until its response is saved, its request fails, or it expires.
+// It's never taken over, as a request that stopped halfway may have already done its work.
+func (r *Repo) ReserveKey(ctx context.Context, endpoint, key, requestHash string) (entities.IdempotentResponse, bool, error) {
+	var reserved bool
+	err := r.db.GetContext(ctx, &reserved, reserveKey, endpoint, key, requestHash)
+	if err == nil {
+		return entities.IdempotentResponse{}, true, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return entities.IdempotentResponse{}, false, fmt.Errorf("could not reserve idempotency key: %w", err)
+	}
+
+	var resp entities.IdempotentResponse
+	if err := r.db.QueryRowContext(ctx, getResponse, endpoint, key).Scan(
+		&resp.RequestHash,
+		&resp.StatusCode,
+		&resp.Body,
+	); err != nil {
+		return entities.IdempotentResponse{}, false, fmt.Errorf("could not get idempotent response: %w", err)
+	}
+
+	return resp, false, nil
+}
+
+func (r *Repo) SaveResponse(ctx context.Context, endpoint, key string, statusCode int, body []byte) error {
+	_, err := r.db.ExecContext(ctx, saveResponse, endpoint, key, statusCode, body)
+	return err
+}
+
+// DeleteKeysBefore removes keys created before the time, in progress or not.
+func (r *Repo) DeleteKeysBefore(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, deleteKeysBefore, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// ReleaseKey frees a key whose request failed, so the client can retry with it.
+func (r *Repo) ReleaseKey(ctx context.Context, endpoint, key string) error {
+	_, err := r.db.ExecContext(ctx, releaseKey, endpoint, key)
+	return err
+}
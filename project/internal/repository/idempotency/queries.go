@@ -0,0 +1,32 @@
+package idempotency
+
+const (
+	reserveKey = `
+INSERT INTO http_idempotency_keys (endpoint, idempotency_key, request_hash)
+VALUES ($1, $2, $3)
+ON CONFLICT (endpoint, idempotency_key) DO NOTHING
+RETURNING true
+`
+
+	getResponse = `
+SELECT request_hash, COALESCE(status_code, 0), COALESCE(response_body, ''::bytea)
+FROM http_idempotency_keys
+WHERE endpoint = $1 AND idempotency_key = $2
+`
+
+	saveResponse = `
+UPDATE http_idempotency_keys
+SET status_code = $3, response_body = $4
+WHERE endpoint = $1 AND idempotency_key = $2
+`
+
+	deleteKeysBefore = `
+DELETE FROM http_idempotency_keys
+WHERE created_at < $1
+`
+
+	releaseKey = `
+DELETE FROM http_idempotency_keys
+WHERE endpoint = $1 AND idempotency_key = $2 AND status_code IS NULL
+`
+)
@@ -0,0 +1,35 @@
+package processed
+
+import (
+	"context"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+type Repo struct {
+	db *sqlx.DB
+}
+
+func NewRepo(db *sqlx.DB) *Repo {
+	return &Repo{db: db}
+}
+
+func (r *Repo) IsProcessed(ctx context.Context, consumerGroup, eventID string) (bool, error) {
+	var processed bool
+	err := r.db.GetContext(ctx, &processed, isProcessed, consumerGroup, eventID)
+	return processed, err
+}
+
+func (r *Repo) MarkProcessed(ctx context.Context, consumerGroup, eventID string) error {
+	_, err := r.db.ExecContext(ctx, markProcessed, consumerGroup, eventID)
+	return err
+}
+
+func (r *Repo) DeleteProcessedBefore(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, deleteProcessedBefore, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
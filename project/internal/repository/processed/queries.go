@@ -0,0 +1,20 @@
+package processed
+
+const (
+	isProcessed = `
+SELECT EXISTS (
+  SELECT 1 FROM processed_events WHERE consumer_group = $1 AND event_id = $2
+)
+`
+
+	markProcessed = `
+INSERT INTO processed_events (consumer_group, event_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING
+`
+
+	deleteProcessedBefore = `
+DELETE FROM processed_events
+WHERE processed_at < $1
+`
+)
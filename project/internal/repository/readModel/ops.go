@@ -4,16 +4,22 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/ThreeDotsLabs/go-event-driven/common/log"
 	"github.com/jmoiron/sqlx"
 	"tickets/internal/entities"
-	"tickets/internal/repository"
+	"tickets/internal/repository/transaction"
 	"time"
 )
 
+var ErrReadModelNotFound = errors.New("read model not found")
+
+const OpsBookingsTable = "read_model_ops_bookings"
+
 type OpsBookingReadModel struct {
-	db *sqlx.DB
+	db    *sqlx.DB
+	table string
 }
 
 func NewOpsBookingReadModel(db *sqlx.DB) OpsBookingReadModel {
@@ -21,39 +27,23 @@ func NewOpsBookingReadModel(db *sqlx.DB) OpsBookingReadModel {
 		panic("db is nil")
 	}
 
-	return OpsBookingReadModel{db: db}
+	return OpsBookingReadModel{db: db, table: OpsBookingsTable}
 }
 
-func (r OpsBookingReadModel) AllReservations() ([]entities.OpsBooking, error) {
-	query := "SELECT payload FROM read_model_ops_bookings"
-	var quaryArgs []any
-
-	rows, err := r.db.Query(query, quaryArgs...)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var result []entities.OpsBooking
-	for rows.Next() {
-		var payload []byte
-		if err := rows.Scan(&payload); err != nil {
-			return nil, err
-		}
-
-		var reservation entities.OpsBooking
-		if err := json.Unmarshal(payload, &reservation); err != nil {
-			return nil, err
-		}
-
-		result = append(result, reservation)
-	}
-
-	return result, nil
+// WithTable returns a copy of the read model that reads and writes the given table.
+// It's used to rebuild the read model into a shadow table.
+func (r OpsBookingReadModel) WithTable(table string) OpsBookingReadModel {
+	r.table = table
+	return r
 }
 
 func (r OpsBookingReadModel) ReservationReadModel(ctx context.Context, bookingID string) (entities.OpsBooking, error) {
-	return r.findReadModelByBookingID(ctx, bookingID, r.db)
+	rm, err := r.findReadModelByBookingID(ctx, bookingID, r.db)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.OpsBooking{}, ErrReadModelNotFound
+	}
+
+	return rm, err
 }
 
 func (r OpsBookingReadModel) OnBookingMade(ctx context.Context, bookingMade *entities.BookingMade) error {
@@ -83,7 +73,7 @@ func (r OpsBookingReadModel) OnTicketBookingConfirmed(ctx context.Context, event
 				log.
 					FromContext(ctx).
 					WithField("ticket_id", event.TicketID).
-					Debug("Creating ticket read model for ticket %s")
+					Debug("Creating ticket read model for ticket")
 			}
 
 			ticket.PriceAmount = event.Price.Amount
@@ -98,7 +88,7 @@ func (r OpsBookingReadModel) OnTicketBookingConfirmed(ctx context.Context, event
 	)
 }
 
-func (r OpsBookingReadModel) OnTicketRefunded(ctx context.Context, event *entities.RefundTicket) error {
+func (r OpsBookingReadModel) OnTicketRefunded(ctx context.Context, event *entities.TicketRefunded) error {
 	return r.updateTicketInBookingReadModel(
 		ctx,
 		event.TicketID,
@@ -145,13 +135,13 @@ func (r OpsBookingReadModel) createReadModel(
 		return err
 	}
 
-	_, err = r.db.ExecContext(ctx, `
+	_, err = r.db.ExecContext(ctx, fmt.Sprintf(`
 		INSERT INTO 
-		    read_model_ops_bookings (payload, booking_id)
+		    %s (payload, booking_id)
 		VALUES
 			($1, $2)
 		ON CONFLICT (booking_id) DO NOTHING; -- read model may be already updated by another event - we don't want to override
-`, payload, booking.BookingID)
+`, r.table), payload, booking.BookingID)
 
 	if err != nil {
 		return fmt.Errorf("could not create read model: %w", err)
@@ -165,7 +155,7 @@ func (r OpsBookingReadModel) updateBookingReadModel(
 	bookingID string,
 	updateFunc func(ticket entities.OpsBooking) (entities.OpsBooking, error),
 ) (err error) {
-	return repository.UpdateInTx(
+	return transaction.UpdateInTx(
 		ctx,
 		r.db,
 		sql.LevelRepeatableRead,
@@ -193,7 +183,7 @@ func (r OpsBookingReadModel) updateTicketInBookingReadModel(
 	ticketID string,
 	updateFunc func(ticket entities.OpsTicket) (entities.OpsTicket, error),
 ) (err error) {
-	return repository.UpdateInTx(
+	return transaction.UpdateInTx(
 		ctx,
 		r.db,
 		sql.LevelRepeatableRead,
@@ -232,13 +222,13 @@ func (r OpsBookingReadModel) updateReadModel(
 		return err
 	}
 
-	_, err = tx.ExecContext(ctx, `
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(`
 		INSERT INTO 
-			read_model_ops_bookings (payload, booking_id)
+			%s (payload, booking_id)
 		VALUES
 			($1, $2)
 		ON CONFLICT (booking_id) DO UPDATE SET payload = excluded.payload;
-		`, payload, rm.BookingID)
+		`, r.table), payload, rm.BookingID)
 	if err != nil {
 		return fmt.Errorf("could not update read model: %w", err)
 	}
@@ -255,7 +245,7 @@ func (r OpsBookingReadModel) findReadModelByTicketID(
 
 	err := db.QueryRowContext(
 		ctx,
-		"SELECT payload FROM read_model_ops_bookings WHERE payload::jsonb -> 'tickets' ? $1",
+		fmt.Sprintf("SELECT payload FROM %s WHERE payload::jsonb -> 'tickets' ? $1", r.table),
 		ticketID,
 	).Scan(&payload)
 	if err != nil {
@@ -274,7 +264,7 @@ func (r OpsBookingReadModel) findReadModelByBookingID(
 
 	err := db.QueryRowContext(
 		ctx,
-		"SELECT payload FROM read_model_ops_bookings WHERE booking_id = $1",
+		fmt.Sprintf("SELECT payload FROM %s WHERE booking_id = $1", r.table),
 		bookingID,
 	).Scan(&payload)
 	if err != nil {
@@ -0,0 +1,111 @@
+package readModel
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"tickets/internal/entities"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// opsCursor points at the last booking of a page. booked_at is kept in the text form it's stored in
+// the payload, so it's compared with exactly the value the database returned.
+type opsCursor struct {
+	BookedAt  string `json:"k"`
+	BookingID string `json:"id"`
+}
+
+func (c opsCursor) encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeOpsCursor(s string) (opsCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return opsCursor{}, ErrInvalidCursor
+	}
+
+	var c opsCursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return opsCursor{}, ErrInvalidCursor
+	}
+	if _, err := uuid.Parse(c.BookingID); err != nil {
+		return opsCursor{}, ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+// ReservationList returns a single page of bookings, the most recently booked first.
+// The booking_id is used as a tie-breaker, so pages stay stable when bookings share booked_at.
+func (r OpsBookingReadModel) ReservationList(ctx context.Context, filter entities.OpsBookingFilter) (entities.OpsBookingPage, error) {
+	limit := filter.Limit
+	if limit <= 0 || limit > entities.OpsBookingsMaxLimit {
+		limit = entities.OpsBookingsDefaultLimit
+	}
+
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	query := fmt.Sprintf("SELECT payload, payload->>'booked_at'\nFROM %s\n", r.table)
+
+	if filter.Cursor != "" {
+		c, err := decodeOpsCursor(filter.Cursor)
+		if err != nil {
+			return entities.OpsBookingPage{}, err
+		}
+
+		query += fmt.Sprintf("WHERE (payload->>'booked_at', booking_id) < (%s, %s::uuid)\n", arg(c.BookedAt), arg(c.BookingID))
+	}
+
+	query += "ORDER BY payload->>'booked_at' DESC, booking_id DESC\nLIMIT " + arg(limit+1)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return entities.OpsBookingPage{}, err
+	}
+
+	defer rows.Close()
+
+	page := entities.OpsBookingPage{Items: []entities.OpsBooking{}}
+	var bookedAt, lastBookedAt string
+
+	for rows.Next() {
+		var payload []byte
+		if err := rows.Scan(&payload, &bookedAt); err != nil {
+			return entities.OpsBookingPage{}, err
+		}
+
+		if len(page.Items) == limit {
+			last := page.Items[len(page.Items)-1]
+			page.NextCursor = opsCursor{BookedAt: lastBookedAt, BookingID: last.BookingID.String()}.encode()
+			break
+		}
+
+		var reservation entities.OpsBooking
+		if err := json.Unmarshal(payload, &reservation); err != nil {
+			return entities.OpsBookingPage{}, err
+		}
+
+		lastBookedAt = bookedAt
+		page.Items = append(page.Items, reservation)
+	}
+
+	if err := rows.Close(); err != nil {
+		return entities.OpsBookingPage{}, err
+	}
+
+	if err := rows.Err(); err != nil {
+		return entities.OpsBookingPage{}, err
+	}
+
+	return page, nil
+}
@@ -0,0 +1,191 @@
+package readModel
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"tickets/internal/entities"
+	"tickets/internal/repository/transaction"
+)
+
+// ShowAvailabilityReadModel keeps the number of booked seats per show,
+// so availability can be read without summing all bookings.
+type ShowAvailabilityReadModel struct {
+	db *sqlx.DB
+}
+
+func NewShowAvailabilityReadModel(db *sqlx.DB) ShowAvailabilityReadModel {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return ShowAvailabilityReadModel{db: db}
+}
+
+func (r ShowAvailabilityReadModel) ShowAvailability(ctx context.Context, showID uuid.UUID) (entities.ShowAvailability, error) {
+	var availability entities.ShowAvailability
+
+	err := r.db.GetContext(ctx, &availability, `
+		SELECT
+			s.show_id,
+			s.number_of_tickets AS total_seats,
+			COALESCE(a.booked_seats, 0) AS booked_seats,
+			s.number_of_tickets - COALESCE(a.booked_seats, 0) AS available_seats
+		FROM shows s
+		LEFT JOIN show_availability a ON a.show_id = s.show_id
+		WHERE s.show_id = $1
+	`, showID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.ShowAvailability{}, ErrReadModelNotFound
+	} else if err != nil {
+		return entities.ShowAvailability{}, fmt.Errorf("could not get show availability: %w", err)
+	}
+
+	return availability, nil
+}
+
+func (r ShowAvailabilityReadModel) OnBookingMade(ctx context.Context, event *entities.BookingMade) error {
+	return transaction.UpdateInTx(
+		ctx,
+		r.db,
+		sql.LevelReadCommitted,
+		func(ctx context.Context, tx *sqlx.Tx) error {
+			return applyChange(ctx, tx, "booking-made:"+event.BookingID.String(), event.BookingID, event.ShowId, event.NumberOfTickets)
+		},
+	)
+}
+
+// OnTicketBookingCanceled releases the seat of the ticket, unless its whole booking was canceled.
+// It runs in another consumer group than OnBookingCanceled, both lock the booking first,
+// so a seat isn't released by both of them.
+func (r ShowAvailabilityReadModel) OnTicketBookingCanceled(ctx context.Context, event *entities.TicketBookingCanceled) error {
+	logger := log.FromContext(ctx).WithField("ticket_id", event.TicketID)
+
+	bookingID, err := uuid.Parse(event.BookingID)
+	if err != nil {
+		logger.Warn("Ticket canceled without a valid booking ID, seat can't be released")
+		return nil
+	}
+
+	return transaction.UpdateInTx(
+		ctx,
+		r.db,
+		sql.LevelReadCommitted,
+		func(ctx context.Context, tx *sqlx.Tx) error {
+			booking, err := lockBooking(ctx, tx, bookingID)
+			if errors.Is(err, sql.ErrNoRows) {
+				logger.WithField("booking_id", bookingID).Warn("Booking of canceled ticket not found, seat can't be released")
+				return nil
+			} else if err != nil {
+				return err
+			}
+
+			if booking.Canceled.Valid {
+				// seats of the whole booking are released by BookingCanceled
+				return nil
+			}
+
+			return applyChange(ctx, tx, "ticket-canceled:"+event.TicketID, bookingID, booking.ShowID, -1)
+		},
+	)
+}
+
+func (r ShowAvailabilityReadModel) OnBookingCanceled(ctx context.Context, event *entities.BookingCanceled) error {
+	return transaction.UpdateInTx(
+		ctx,
+		r.db,
+		sql.LevelReadCommitted,
+		func(ctx context.Context, tx *sqlx.Tx) error {
+			_, err := lockBooking(ctx, tx, event.BookingID)
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("canceled booking %s not found", event.BookingID)
+			} else if err != nil {
+				return err
+			}
+
+			// tickets canceled before the booking have already released their seats
+			var released int
+			err = tx.GetContext(ctx, &released, `
+				SELECT COUNT(*)
+				FROM show_availability_changes
+				WHERE booking_id = $1 AND change_id LIKE 'ticket-canceled:%'
+			`, event.BookingID)
+			if err != nil {
+				return fmt.Errorf("could not count released seats of booking %s: %w", event.BookingID, err)
+			}
+
+			return applyChange(
+				ctx,
+				tx,
+				"booking-canceled:"+event.BookingID.String(),
+				event.BookingID,
+				event.ShowID,
+				-(event.NumberOfTickets - released),
+			)
+		},
+	)
+}
+
+type lockedBooking struct {
+	ShowID   uuid.UUID    `db:"show_id"`
+	Canceled sql.NullTime `db:"canceled_at"`
+}
+
+// lockBooking locks the booking row until the transaction ends.
+func lockBooking(ctx context.Context, tx *sqlx.Tx, bookingID uuid.UUID) (lockedBooking, error) {
+	var booking lockedBooking
+	err := tx.GetContext(ctx, &booking, "SELECT show_id, canceled_at FROM bookings WHERE booking_id = $1 FOR UPDATE", bookingID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return lockedBooking{}, err
+	} else if err != nil {
+		return lockedBooking{}, fmt.Errorf("could not lock booking %s: %w", bookingID, err)
+	}
+
+	return booking, nil
+}
+
+// applyChange adds seats to the booked seats of the show.
+// Each change is applied only once, so redelivered events don't change the availability twice.
+func applyChange(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	changeID string,
+	bookingID uuid.UUID,
+	showID uuid.UUID,
+	seats int,
+) error {
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO show_availability_changes (change_id, booking_id, show_id, seats)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (change_id) DO NOTHING
+	`, changeID, bookingID, showID, seats)
+	if err != nil {
+		return fmt.Errorf("could not save show availability change: %w", err)
+	}
+
+	inserted, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if inserted == 0 {
+		// change was already applied
+		return nil
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO show_availability (show_id, booked_seats, last_update)
+		VALUES ($1, $2, now())
+		ON CONFLICT (show_id) DO UPDATE SET
+			booked_seats = show_availability.booked_seats + excluded.booked_seats,
+			last_update = excluded.last_update
+	`, showID, seats)
+	if err != nil {
+		return fmt.Errorf("could not update show availability: %w", err)
+	}
+
+	return nil
+}
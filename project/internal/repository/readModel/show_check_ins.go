@@ -0,0 +1,93 @@
+package readModel
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"tickets/internal/entities"
+	"tickets/internal/repository/transaction"
+)
+
+// ShowCheckInsReadModel counts checked in tickets per show, so door staff can see how many people came in.
+type ShowCheckInsReadModel struct {
+	db *sqlx.DB
+}
+
+func NewShowCheckInsReadModel(db *sqlx.DB) ShowCheckInsReadModel {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return ShowCheckInsReadModel{db: db}
+}
+
+func (r ShowCheckInsReadModel) ShowCheckIns(ctx context.Context, showID uuid.UUID) (entities.ShowCheckIns, error) {
+	var checkIns entities.ShowCheckIns
+
+	err := r.db.GetContext(ctx, &checkIns, `
+		SELECT
+			s.show_id,
+			COALESCE(c.checked_in, 0) AS checked_in
+		FROM shows s
+		LEFT JOIN show_check_ins c ON c.show_id = s.show_id
+		WHERE s.show_id = $1
+	`, showID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.ShowCheckIns{}, ErrReadModelNotFound
+	} else if err != nil {
+		return entities.ShowCheckIns{}, fmt.Errorf("could not get show check-ins: %w", err)
+	}
+
+	return checkIns, nil
+}
+
+// OnTicketCheckedIn counts each ticket once, so redelivered events don't change the counter twice.
+func (r ShowCheckInsReadModel) OnTicketCheckedIn(ctx context.Context, event *entities.TicketCheckedIn) error {
+	showID, err := uuid.Parse(event.ShowID)
+	if err != nil {
+		log.FromContext(ctx).WithField("ticket_id", event.TicketID).Warn("Ticket checked in without a show, it isn't counted")
+		return nil
+	}
+
+	return transaction.UpdateInTx(
+		ctx,
+		r.db,
+		sql.LevelReadCommitted,
+		func(ctx context.Context, tx *sqlx.Tx) error {
+			res, err := tx.ExecContext(ctx, `
+				INSERT INTO show_check_in_tickets (ticket_id, show_id)
+				VALUES ($1, $2)
+				ON CONFLICT (ticket_id) DO NOTHING
+			`, event.TicketID, showID)
+			if err != nil {
+				return fmt.Errorf("could not save checked in ticket: %w", err)
+			}
+
+			inserted, err := res.RowsAffected()
+			if err != nil {
+				return err
+			}
+			if inserted == 0 {
+				// ticket was already counted
+				return nil
+			}
+
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO show_check_ins (show_id, checked_in, last_update)
+				VALUES ($1, 1, now())
+				ON CONFLICT (show_id) DO UPDATE SET
+					checked_in = show_check_ins.checked_in + 1,
+					last_update = excluded.last_update
+			`, showID)
+			if err != nil {
+				return fmt.Errorf("could not update show check-ins: %w", err)
+			}
+
+			return nil
+		},
+	)
+}
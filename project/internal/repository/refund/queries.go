@@ -0,0 +1,65 @@
+package refund
+
+const (
+	startRefund = `
+INSERT INTO refunds (ticket_id, idempotency_key, status)
+VALUES ($1, $2, 'started')
+ON CONFLICT (ticket_id) DO NOTHING
+`
+
+	getRefund = `
+SELECT ticket_id, idempotency_key, status, attempts, last_error, failed_at, next_attempt_at, created_at, updated_at
+FROM refunds
+WHERE ticket_id = $1
+`
+
+	markReceiptVoided = `
+UPDATE refunds
+SET status = 'receipt_voided', updated_at = now()
+WHERE ticket_id = $1 AND status = 'started'
+`
+
+	completeRefund = `
+UPDATE refunds
+SET status = 'completed', next_attempt_at = NULL, updated_at = now()
+WHERE ticket_id = $1 AND status <> 'completed'
+`
+
+	recordFailure = `
+UPDATE refunds
+SET attempts = attempts + 1,
+    last_error = $2,
+    failed_at = CASE WHEN attempts + 1 >= $3 THEN now() ELSE NULL END,
+    next_attempt_at = CASE WHEN attempts + 1 >= $3 THEN NULL ELSE now() + $4 * interval '1 microsecond' END,
+    updated_at = now()
+WHERE ticket_id = $1
+RETURNING ticket_id, idempotency_key, status, attempts, last_error, failed_at, next_attempt_at, created_at, updated_at
+`
+
+	resetFailedRefund = `
+UPDATE refunds
+SET attempts = 0, failed_at = NULL, updated_at = now()
+WHERE ticket_id = $1 AND failed_at IS NOT NULL
+RETURNING ticket_id, idempotency_key, status, attempts, last_error, failed_at, next_attempt_at, created_at, updated_at
+`
+
+	refundsToRetry = `
+SELECT ticket_id, idempotency_key, status, attempts, last_error, failed_at, next_attempt_at, created_at, updated_at
+FROM refunds
+WHERE next_attempt_at <= now() AND failed_at IS NULL AND status <> 'completed'
+ORDER BY next_attempt_at
+`
+
+	clearRetry = `
+UPDATE refunds
+SET next_attempt_at = NULL, updated_at = now()
+WHERE ticket_id = $1 AND next_attempt_at <= now()
+`
+
+	refundList = `
+SELECT ticket_id, idempotency_key, status, attempts, last_error, failed_at, next_attempt_at, created_at, updated_at
+FROM refunds
+WHERE ($1 = '' OR status = $1) AND (NOT $2 OR failed_at IS NOT NULL)
+ORDER BY updated_at DESC
+`
+)
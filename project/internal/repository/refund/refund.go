@@ -0,0 +1,161 @@
+package refund
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"tickets/internal/broker/outbox"
+	"tickets/internal/entities"
+	"tickets/internal/repository/transaction"
+	"time"
+)
+
+var (
+	ErrRefundNotFound  = errors.New("refund not found")
+	ErrRefundNotFailed = errors.New("refund is not failed")
+)
+
+// Repo stores the state of refund processes.
+// Finishing a refund, successfully or not, publishes the matching event in the same transaction.
+type Repo struct {
+	db *sqlx.DB
+}
+
+func NewRepo(db *sqlx.DB) *Repo {
+	return &Repo{db: db}
+}
+
+// StartRefund creates the refund if it doesn't exist yet and returns its current state.
+func (r *Repo) StartRefund(ctx context.Context, ticketID string, idempotencyKey string) (entities.Refund, error) {
+	if _, err := r.db.ExecContext(ctx, startRefund, ticketID, idempotencyKey); err != nil {
+		return entities.Refund{}, fmt.Errorf("could not start refund: %w", err)
+	}
+
+	return r.RefundByTicketID(ctx, ticketID)
+}
+
+func (r *Repo) RefundByTicketID(ctx context.Context, ticketID string) (entities.Refund, error) {
+	var refund entities.Refund
+	err := r.db.GetContext(ctx, &refund, getRefund, ticketID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.Refund{}, ErrRefundNotFound
+	} else if err != nil {
+		return entities.Refund{}, fmt.Errorf("could not get refund: %w", err)
+	}
+
+	return refund, nil
+}
+
+func (r *Repo) MarkReceiptVoided(ctx context.Context, ticketID string) error {
+	_, err := r.db.ExecContext(ctx, markReceiptVoided, ticketID)
+	return err
+}
+
+func (r *Repo) CompleteRefund(ctx context.Context, ticketID string) error {
+	return r.updateAndPublish(ctx, func(ctx context.Context, tx *sqlx.Tx) (any, error) {
+		res, err := tx.ExecContext(ctx, completeRefund, ticketID)
+		if err != nil {
+			return nil, fmt.Errorf("could not complete refund: %w", err)
+		}
+
+		updated, err := res.RowsAffected()
+		if err != nil || updated == 0 {
+			// already completed
+			return nil, err
+		}
+
+		return entities.TicketRefunded{
+			Header:   entities.NewEventHeader(ticketID),
+			TicketID: ticketID,
+		}, nil
+	})
+}
+
+// RecordFailure saves the failure of a refund step and schedules the next attempt after retryDelay.
+// After maxAttempts failures the refund is marked as failed and waits for manual intervention.
+func (r *Repo) RecordFailure(ctx context.Context, ticketID string, reason string, maxAttempts int, retryDelay time.Duration) (entities.Refund, error) {
+	var refund entities.Refund
+
+	err := r.updateAndPublish(ctx, func(ctx context.Context, tx *sqlx.Tx) (any, error) {
+		if err := tx.GetContext(ctx, &refund, recordFailure, ticketID, reason, maxAttempts, retryDelay.Microseconds()); err != nil {
+			return nil, fmt.Errorf("could not record refund failure: %w", err)
+		}
+
+		if refund.FailedAt == nil {
+			return nil, nil
+		}
+
+		return entities.TicketRefundFailed{
+			Header:   entities.NewEventHeader(ticketID),
+			TicketID: ticketID,
+			Status:   refund.Status,
+			Reason:   reason,
+		}, nil
+	})
+
+	return refund, err
+}
+
+// ResetFailedRefund clears the failure of the refund, so it can be retried.
+func (r *Repo) ResetFailedRefund(ctx context.Context, ticketID string) (entities.Refund, error) {
+	var refund entities.Refund
+	err := r.db.GetContext(ctx, &refund, resetFailedRefund, ticketID)
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, err := r.RefundByTicketID(ctx, ticketID); err != nil {
+			return entities.Refund{}, err
+		}
+		return entities.Refund{}, ErrRefundNotFailed
+	} else if err != nil {
+		return entities.Refund{}, fmt.Errorf("could not reset refund: %w", err)
+	}
+
+	return refund, nil
+}
+
+// RefundsToRetry returns the refunds whose next attempt is due.
+func (r *Repo) RefundsToRetry(ctx context.Context) ([]entities.Refund, error) {
+	refunds := []entities.Refund{}
+	if err := r.db.SelectContext(ctx, &refunds, refundsToRetry); err != nil {
+		return nil, fmt.Errorf("could not get refunds to retry: %w", err)
+	}
+
+	return refunds, nil
+}
+
+// ClearRetry removes the due attempt of the refund once it's been sent.
+// An attempt scheduled again in the meantime is kept.
+func (r *Repo) ClearRetry(ctx context.Context, ticketID string) error {
+	if _, err := r.db.ExecContext(ctx, clearRetry, ticketID); err != nil {
+		return fmt.Errorf("could not clear refund retry: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repo) RefundList(ctx context.Context, status string, onlyFailed bool) ([]entities.Refund, error) {
+	refunds := []entities.Refund{}
+	if err := r.db.SelectContext(ctx, &refunds, refundList, status, onlyFailed); err != nil {
+		return nil, fmt.Errorf("could not list refunds: %w", err)
+	}
+
+	return refunds, nil
+}
+
+// updateAndPublish runs fn in a transaction and publishes the event it returns (if any) through the outbox.
+func (r *Repo) updateAndPublish(ctx context.Context, fn func(ctx context.Context, tx *sqlx.Tx) (any, error)) error {
+	return transaction.UpdateInTx(
+		ctx,
+		r.db,
+		sql.LevelReadCommitted,
+		func(ctx context.Context, tx *sqlx.Tx) error {
+			ev, err := fn(ctx, tx)
+			if err != nil || ev == nil {
+				return err
+			}
+
+			return outbox.PublishInTx(ctx, tx.Tx, ev)
+		},
+	)
+}
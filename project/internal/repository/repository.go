@@ -6,33 +6,91 @@ import (
 	"github.com/jmoiron/sqlx"
 	"tickets/internal/entities"
 	"tickets/internal/repository/booking"
+	"tickets/internal/repository/events"
+	"tickets/internal/repository/idempotency"
+	"tickets/internal/repository/processed"
 	"tickets/internal/repository/readModel"
+	"tickets/internal/repository/refund"
 	"tickets/internal/repository/show"
 	"tickets/internal/repository/ticket"
+	"time"
 )
 
 type Ticket interface {
 	SaveTicket(ctx context.Context, confirmed entities.TicketBookingConfirmed) error
-	DeleteTicket(ctx context.Context, ticketID string) error
-	TicketList(ctx context.Context) ([]entities.TicketList, error)
+	CancelTicket(ctx context.Context, canceled entities.TicketBookingCanceled) error
+	TicketList(ctx context.Context, filter entities.TicketFilter) (entities.TicketPage, error)
+	GetByID(ctx context.Context, ticketID string) (entities.Ticket, error)
+	RefundTicket(ctx context.Context, ticketID string, refundedAt time.Time) error
+	MarkTicketPrinted(ctx context.Context, ticketID string, printedAt time.Time) error
+	MarkReceiptIssued(ctx context.Context, ticketID string, issuedAt time.Time) error
+	RecordStatusChanges(ctx context.Context, idempotencyKey string, tickets []entities.Ticket) error
+	CheckInTicket(ctx context.Context, ticketID string, checkedInAt time.Time) (entities.CheckIn, error)
 }
 
 type Show interface {
 	NewShow(ctx context.Context, show entities.Show) (string, error)
 	ShowByID(ctx context.Context, showId uuid.UUID) (entities.Show, error)
+	ShowByBookingID(ctx context.Context, bookingID uuid.UUID) (entities.Show, error)
+	ShowList(ctx context.Context) ([]entities.Show, error)
+	UpdateShow(ctx context.Context, showID uuid.UUID, update entities.ShowUpdate) (entities.Show, error)
+	CancelShow(ctx context.Context, showID uuid.UUID) error
 }
 
 type Booking interface {
 	BookTicket(ctx context.Context, booking entities.Booking) (string, error)
+	CancelBooking(ctx context.Context, bookingID uuid.UUID) error
 }
 
 type Ops interface {
-	AllReservations() ([]entities.OpsBooking, error)
+	ReservationList(ctx context.Context, filter entities.OpsBookingFilter) (entities.OpsBookingPage, error)
 	ReservationReadModel(ctx context.Context, bookingID string) (entities.OpsBooking, error)
 	OnBookingMade(ctx context.Context, bookingMade *entities.BookingMade) error
 	OnTicketBookingConfirmed(ctx context.Context, event *entities.TicketBookingConfirmed) error
-	OnTicketRefunded(ctx context.Context, event *entities.RefundTicket) error
+	OnTicketRefunded(ctx context.Context, event *entities.TicketRefunded) error
 	OnTicketPrinted(ctx context.Context, event *entities.TicketPrinted) error
+	OnTicketReceiptIssued(ctx context.Context, issued *entities.TicketReceiptIssued) error
+}
+
+type ShowAvailability interface {
+	ShowAvailability(ctx context.Context, showID uuid.UUID) (entities.ShowAvailability, error)
+	OnBookingMade(ctx context.Context, event *entities.BookingMade) error
+	OnTicketBookingCanceled(ctx context.Context, event *entities.TicketBookingCanceled) error
+	OnBookingCanceled(ctx context.Context, event *entities.BookingCanceled) error
+}
+
+type ShowCheckIns interface {
+	ShowCheckIns(ctx context.Context, showID uuid.UUID) (entities.ShowCheckIns, error)
+	OnTicketCheckedIn(ctx context.Context, event *entities.TicketCheckedIn) error
+}
+
+type Refund interface {
+	StartRefund(ctx context.Context, ticketID string, idempotencyKey string) (entities.Refund, error)
+	MarkReceiptVoided(ctx context.Context, ticketID string) error
+	CompleteRefund(ctx context.Context, ticketID string) error
+	RecordFailure(ctx context.Context, ticketID string, reason string, maxAttempts int, retryDelay time.Duration) (entities.Refund, error)
+	ResetFailedRefund(ctx context.Context, ticketID string) (entities.Refund, error)
+	RefundsToRetry(ctx context.Context) ([]entities.Refund, error)
+	ClearRetry(ctx context.Context, ticketID string) error
+	RefundList(ctx context.Context, status string, onlyFailed bool) ([]entities.Refund, error)
+}
+
+type Events interface {
+	AppendEvent(ctx context.Context, event entities.StoredEvent) error
+	EventsAfter(ctx context.Context, afterSeq int64, fn func(event entities.StoredEvent) error) (int64, error)
+}
+
+type Idempotency interface {
+	ReserveKey(ctx context.Context, endpoint, key, requestHash string) (entities.IdempotentResponse, bool, error)
+	SaveResponse(ctx context.Context, endpoint, key string, statusCode int, body []byte) error
+	ReleaseKey(ctx context.Context, endpoint, key string) error
+	DeleteKeysBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type ProcessedEvents interface {
+	IsProcessed(ctx context.Context, consumerGroup, eventID string) (bool, error)
+	MarkProcessed(ctx context.Context, consumerGroup, eventID string) error
+	DeleteProcessedBefore(ctx context.Context, before time.Time) (int64, error)
 }
 
 type Repository struct {
@@ -40,6 +98,13 @@ type Repository struct {
 	Show    Show
 	Booking Booking
 	Ops     Ops
+	Events  Events
+	Refund  Refund
+
+	Idempotency      Idempotency
+	ProcessedEvents  ProcessedEvents
+	ShowAvailability ShowAvailability
+	ShowCheckIns     ShowCheckIns
 }
 
 func NewRepository(db *sqlx.DB) *Repository {
@@ -48,5 +113,12 @@ func NewRepository(db *sqlx.DB) *Repository {
 		Show:    show.NewRepo(db),
 		Booking: booking.NewRepo(db),
 		Ops:     readModel.NewOpsBookingReadModel(db),
+		Events:  events.NewRepo(db),
+		Refund:  refund.NewRepo(db),
+
+		Idempotency:      idempotency.NewRepo(db),
+		ProcessedEvents:  processed.NewRepo(db),
+		ShowAvailability: readModel.NewShowAvailabilityReadModel(db),
+		ShowCheckIns:     readModel.NewShowCheckInsReadModel(db),
 	}
 }
@@ -21,7 +21,95 @@ CREATE TABLE IF NOT EXISTS bookings(
 	number_of_tickets INTEGER NOT NULL,
 	customer_email VARCHAR NOT NULL 
 );
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS canceled_at TIMESTAMP;
+ALTER TABLE shows ADD COLUMN IF NOT EXISTS canceled_at TIMESTAMP;
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS status VARCHAR(32) NOT NULL DEFAULT 'confirmed';
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS confirmed_at TIMESTAMP;
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS printed_at TIMESTAMP;
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS receipt_issued_at TIMESTAMP;
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS canceled_at TIMESTAMP;
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS refunded_at TIMESTAMP;
+CREATE INDEX IF NOT EXISTS tickets_status_idx ON tickets (status);
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS booking_id UUID;
+CREATE INDEX IF NOT EXISTS tickets_booking_id_idx ON tickets (booking_id);
+CREATE INDEX IF NOT EXISTS tickets_confirmed_at_idx ON tickets (confirmed_at, ticket_id);
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS checked_in_at TIMESTAMP;
+CREATE TABLE IF NOT EXISTS ticket_status_changes (
+    idempotency_key VARCHAR NOT NULL,
+    ticket_id VARCHAR NOT NULL,
+    status VARCHAR(32) NOT NULL,
+    received_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (idempotency_key, ticket_id)
+);
 CREATE TABLE IF NOT EXISTS read_model_ops_bookings (
     booking_id UUID PRIMARY KEY,
     payload JSONB NOT NULL
-);`
+);
+CREATE INDEX IF NOT EXISTS read_model_ops_bookings_booked_at_idx ON read_model_ops_bookings ((payload->>'booked_at') DESC, booking_id DESC);
+CREATE TABLE IF NOT EXISTS show_availability (
+    show_id UUID PRIMARY KEY,
+    booked_seats INTEGER NOT NULL DEFAULT 0,
+    last_update TIMESTAMP NOT NULL DEFAULT now()
+);
+CREATE TABLE IF NOT EXISTS show_availability_changes (
+    change_id VARCHAR PRIMARY KEY,
+    show_id UUID NOT NULL,
+    seats INTEGER NOT NULL
+);
+ALTER TABLE show_availability_changes ADD COLUMN IF NOT EXISTS booking_id UUID;
+CREATE TABLE IF NOT EXISTS show_check_ins (
+    show_id UUID PRIMARY KEY,
+    checked_in INTEGER NOT NULL DEFAULT 0,
+    last_update TIMESTAMP NOT NULL DEFAULT now()
+);
+CREATE TABLE IF NOT EXISTS show_check_in_tickets (
+    ticket_id VARCHAR PRIMARY KEY,
+    show_id UUID NOT NULL
+);
+CREATE TABLE IF NOT EXISTS refunds (
+    ticket_id VARCHAR PRIMARY KEY,
+    idempotency_key VARCHAR NOT NULL,
+    status VARCHAR NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error VARCHAR NOT NULL DEFAULT '',
+    failed_at TIMESTAMP,
+    next_attempt_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+CREATE TABLE IF NOT EXISTS http_idempotency_keys (
+    endpoint VARCHAR NOT NULL,
+    idempotency_key VARCHAR NOT NULL,
+    request_hash VARCHAR NOT NULL,
+    status_code INTEGER,
+    response_body BYTEA,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (endpoint, idempotency_key)
+);
+CREATE INDEX IF NOT EXISTS http_idempotency_keys_created_at_idx ON http_idempotency_keys (created_at);
+CREATE TABLE IF NOT EXISTS processed_events (
+    consumer_group VARCHAR NOT NULL,
+    event_id VARCHAR NOT NULL,
+    processed_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (consumer_group, event_id)
+);
+CREATE INDEX IF NOT EXISTS processed_events_processed_at_idx ON processed_events (processed_at);
+CREATE TABLE IF NOT EXISTS events (
+    seq BIGSERIAL PRIMARY KEY,
+    event_id UUID NOT NULL UNIQUE,
+    event_name VARCHAR(255) NOT NULL,
+    payload JSONB NOT NULL,
+    published_at TIMESTAMP NOT NULL,
+    stored_at TIMESTAMP NOT NULL DEFAULT now()
+);
+ALTER TABLE events ADD COLUMN IF NOT EXISTS idempotency_key VARCHAR NOT NULL DEFAULT '';
+ALTER TABLE events ADD COLUMN IF NOT EXISTS correlation_id VARCHAR NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS events_stored_at_idx ON events (stored_at);
+CREATE OR REPLACE FUNCTION events_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'events table is append-only';
+END;
+$$ LANGUAGE plpgsql;
+CREATE OR REPLACE TRIGGER events_append_only
+    BEFORE UPDATE OR DELETE ON events
+    FOR EACH ROW EXECUTE FUNCTION events_append_only();`
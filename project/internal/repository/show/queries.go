@@ -8,5 +8,49 @@ INSERT INTO shows (
   :show_id, :dead_nation_id, :number_of_tickets, :start_time, :title, :venue
 )
 RETURNING show_id
+`
+
+	showByID = `
+SELECT show_id, dead_nation_id, number_of_tickets, start_time, title, venue, canceled_at
+FROM shows
+WHERE show_id = $1
+`
+
+	showByBookingID = `
+SELECT s.show_id, s.dead_nation_id, s.number_of_tickets, s.start_time, s.title, s.venue, s.canceled_at
+FROM shows s
+JOIN bookings b ON b.show_id = s.show_id
+WHERE b.booking_id = $1
+`
+
+	showList = `
+SELECT show_id, dead_nation_id, number_of_tickets, start_time, title, venue, canceled_at
+FROM shows
+ORDER BY start_time, show_id
+`
+
+	lockShow = `
+SELECT show_id, dead_nation_id, number_of_tickets, start_time, title, venue, canceled_at
+FROM shows
+WHERE show_id = $1
+FOR UPDATE
+`
+
+	bookedSeats = `
+SELECT COALESCE(SUM(number_of_tickets), 0)
+FROM bookings
+WHERE show_id = $1 AND canceled_at IS NULL
+`
+
+	updateShow = `
+UPDATE shows
+SET number_of_tickets = $2, start_time = $3, title = $4, venue = $5
+WHERE show_id = $1
+`
+
+	cancelShow = `
+UPDATE shows
+SET canceled_at = $2
+WHERE show_id = $1
 `
 )
@@ -2,12 +2,30 @@ package show
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"tickets/internal/broker/outbox"
 	"tickets/internal/entities"
+	"tickets/internal/repository/transaction"
+	"time"
 )
 
+var (
+	ErrShowNotFound = errors.New("show not found")
+	ErrShowCanceled = errors.New("show is canceled")
+)
+
+type SeatsBelowBookedError struct {
+	Booked int
+}
+
+func (e SeatsBelowBookedError) Error() string {
+	return fmt.Sprintf("number of tickets can't be lower than already booked seats: %d", e.Booked)
+}
+
 type Repo struct {
 	db *sqlx.DB
 }
@@ -36,14 +54,144 @@ func (r *Repo) NewShow(ctx context.Context, show entities.Show) (string, error)
 
 func (r *Repo) ShowByID(ctx context.Context, showId uuid.UUID) (entities.Show, error) {
 	var show entities.Show
-	var err = r.db.GetContext(ctx, &show, `
-		SELECT * 
-		FROM shows
-		WHERE show_id = $1
-	`, showId)
+	err := r.db.GetContext(ctx, &show, showByID, showId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.Show{}, ErrShowNotFound
+	}
 	if err != nil {
 		return entities.Show{}, fmt.Errorf("could not get show: %w", err)
 	}
 
 	return show, nil
 }
+
+func (r *Repo) ShowByBookingID(ctx context.Context, bookingID uuid.UUID) (entities.Show, error) {
+	var show entities.Show
+	err := r.db.GetContext(ctx, &show, showByBookingID, bookingID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.Show{}, ErrShowNotFound
+	}
+	if err != nil {
+		return entities.Show{}, fmt.Errorf("could not get show of booking: %w", err)
+	}
+
+	return show, nil
+}
+
+func (r *Repo) ShowList(ctx context.Context) ([]entities.Show, error) {
+	shows := []entities.Show{}
+	if err := r.db.SelectContext(ctx, &shows, showList); err != nil {
+		return nil, fmt.Errorf("could not list shows: %w", err)
+	}
+
+	return shows, nil
+}
+
+// UpdateShow applies the update and publishes ShowUpdated in the same transaction.
+// The number of tickets can't go below the seats that are already booked.
+func (r *Repo) UpdateShow(ctx context.Context, showID uuid.UUID, update entities.ShowUpdate) (entities.Show, error) {
+	var show entities.Show
+
+	err := transaction.UpdateInTxWithRetry(
+		ctx,
+		r.db,
+		sql.LevelReadCommitted,
+		func(ctx context.Context, tx *sqlx.Tx) error {
+			var err error
+			show, err = lockShowForUpdate(ctx, tx, showID)
+			if err != nil {
+				return err
+			}
+			if show.CanceledAt != nil {
+				return ErrShowCanceled
+			}
+
+			if update.NumberOfTickets != nil {
+				var booked int
+				if err := tx.GetContext(ctx, &booked, bookedSeats, showID); err != nil {
+					return fmt.Errorf("could not count booked seats: %w", err)
+				}
+				if *update.NumberOfTickets < booked {
+					return SeatsBelowBookedError{Booked: booked}
+				}
+				show.NumberOfTicket = *update.NumberOfTickets
+			}
+			if update.StartTime != nil {
+				show.StartTime = *update.StartTime
+			}
+			if update.Title != nil {
+				show.Title = *update.Title
+			}
+			if update.Venue != nil {
+				show.Venue = *update.Venue
+			}
+
+			if _, err := tx.ExecContext(ctx, updateShow,
+				showID,
+				show.NumberOfTicket,
+				show.StartTime,
+				show.Title,
+				show.Venue,
+			); err != nil {
+				return fmt.Errorf("could not update show: %w", err)
+			}
+
+			return outbox.PublishInTx(ctx, tx.Tx, entities.ShowUpdated{
+				Header:          entities.NewEventHeader(""),
+				ShowID:          show.ShowID,
+				NumberOfTickets: show.NumberOfTicket,
+				StartTime:       show.StartTime,
+				Title:           show.Title,
+				Venue:           show.Venue,
+			})
+		},
+	)
+	if err != nil {
+		return entities.Show{}, err
+	}
+
+	return show, nil
+}
+
+// CancelShow marks the show as canceled and publishes ShowCanceled in the same transaction.
+// Canceling an already canceled show does nothing.
+func (r *Repo) CancelShow(ctx context.Context, showID uuid.UUID) error {
+	return transaction.UpdateInTxWithRetry(
+		ctx,
+		r.db,
+		sql.LevelReadCommitted,
+		func(ctx context.Context, tx *sqlx.Tx) error {
+			show, err := lockShowForUpdate(ctx, tx, showID)
+			if err != nil {
+				return err
+			}
+			if show.CanceledAt != nil {
+				return nil
+			}
+
+			canceledAt := time.Now().UTC()
+			if _, err := tx.ExecContext(ctx, cancelShow, showID, canceledAt); err != nil {
+				return fmt.Errorf("could not cancel show: %w", err)
+			}
+
+			return outbox.PublishInTx(ctx, tx.Tx, entities.ShowCanceled{
+				Header:     entities.NewEventHeader("show-canceled-" + showID.String()),
+				ShowID:     show.ShowID,
+				CanceledAt: canceledAt,
+			})
+		},
+	)
+}
+
+func lockShowForUpdate(ctx context.Context, tx *sqlx.Tx, showID uuid.UUID) (entities.Show, error) {
+	var show entities.Show
+	err := tx.GetContext(ctx, &show, lockShow, showID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.Show{}, ErrShowNotFound
+	}
+	if err != nil {
+		return entities.Show{}, fmt.Errorf("could not lock show: %w", err)
+	}
+
+	return show, nil
+}
@@ -0,0 +1,73 @@
+package ticket
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"tickets/internal/broker/outbox"
+	"tickets/internal/entities"
+	"tickets/internal/repository/transaction"
+	"time"
+)
+
+var (
+	ErrTicketNotFound         = errors.New("ticket not found")
+	ErrTicketAlreadyCheckedIn = errors.New("ticket already checked in")
+	ErrTicketNotConfirmed     = errors.New("ticket is not confirmed")
+)
+
+// CheckInTicket marks a confirmed ticket as checked in and publishes TicketCheckedIn in the same transaction.
+func (r *Repo) CheckInTicket(ctx context.Context, ticketID string, checkedInAt time.Time) (entities.CheckIn, error) {
+	var checkIn entities.CheckIn
+
+	err := transaction.UpdateInTx(
+		ctx,
+		r.db,
+		sql.LevelReadCommitted,
+		func(ctx context.Context, tx *sqlx.Tx) error {
+			err := tx.GetContext(ctx, &checkIn, checkInTicket, ticketID, checkedInAt)
+			if errors.Is(err, sql.ErrNoRows) {
+				return checkInRejection(ctx, tx, ticketID)
+			}
+			if err != nil {
+				return fmt.Errorf("could not check in ticket: %w", err)
+			}
+
+			return outbox.PublishInTx(ctx, tx.Tx, entities.TicketCheckedIn{
+				Header:      entities.NewEventHeader("check-in-" + checkIn.TicketID),
+				TicketID:    checkIn.TicketID,
+				BookingID:   checkIn.BookingID,
+				ShowID:      checkIn.ShowID,
+				CheckedInAt: checkIn.CheckedInAt,
+			})
+		},
+	)
+	if err != nil {
+		return entities.CheckIn{}, err
+	}
+
+	return checkIn, nil
+}
+
+// checkInRejection tells why the ticket couldn't be checked in.
+func checkInRejection(ctx context.Context, tx *sqlx.Tx, ticketID string) error {
+	var (
+		status    string
+		checkedIn bool
+	)
+	err := tx.QueryRowContext(ctx, ticketCheckInState, ticketID).Scan(&status, &checkedIn)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrTicketNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("could not get ticket: %w", err)
+	}
+
+	if checkedIn {
+		return ErrTicketAlreadyCheckedIn
+	}
+
+	return fmt.Errorf("%w: ticket is %s", ErrTicketNotConfirmed, status)
+}
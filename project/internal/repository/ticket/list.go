@@ -0,0 +1,181 @@
+package ticket
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"tickets/internal/entities"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+type sortColumn struct {
+	expr string
+	cast string
+}
+
+var sortColumns = map[string]sortColumn{
+	"confirmed_at": {expr: "COALESCE(t.confirmed_at, 'epoch'::timestamp)", cast: "timestamp"},
+	"price":        {expr: "t.price_amount", cast: "numeric"},
+	"ticket_id":    {expr: "t.ticket_id", cast: "uuid"},
+}
+
+// cursor points at the last row of a page. The sort key is kept in its Postgres text form,
+// so it's compared with exactly the value the database returned.
+type cursor struct {
+	Sort     string `json:"s"`
+	Key      string `json:"k"`
+	TicketID string `json:"id"`
+}
+
+func (c cursor) encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor{}, ErrInvalidCursor
+	}
+
+	var c cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.TicketID == "" {
+		return cursor{}, ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+// TicketList returns a single page of tickets, the ticket_id is used as a tie-breaker
+// so pages stay stable when many tickets share the same sort key.
+func (r *Repo) TicketList(ctx context.Context, filter entities.TicketFilter) (entities.TicketPage, error) {
+	sortName := strings.TrimPrefix(filter.Sort, "-")
+	if sortName == "" {
+		sortName = "confirmed_at"
+	}
+	sortCol, ok := sortColumns[sortName]
+	if !ok {
+		return entities.TicketPage{}, fmt.Errorf("unknown sort field %q", sortName)
+	}
+	desc := strings.HasPrefix(filter.Sort, "-")
+
+	limit := filter.Limit
+	if limit <= 0 || limit > entities.TicketsMaxLimit {
+		limit = entities.TicketsDefaultLimit
+	}
+
+	var (
+		conditions []string
+		args       []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Status != "" {
+		conditions = append(conditions, "t.status = "+arg(filter.Status))
+	}
+	if filter.CustomerEmail != "" {
+		conditions = append(conditions, "t.customer_email = "+arg(filter.CustomerEmail))
+	}
+	if filter.Currency != "" {
+		conditions = append(conditions, "t.price_currency = "+arg(filter.Currency))
+	}
+	if filter.MinPrice != "" {
+		conditions = append(conditions, "t.price_amount >= "+arg(filter.MinPrice)+"::numeric")
+	}
+	if filter.MaxPrice != "" {
+		conditions = append(conditions, "t.price_amount <= "+arg(filter.MaxPrice)+"::numeric")
+	}
+	if filter.BookingID != "" {
+		conditions = append(conditions, "t.booking_id = "+arg(filter.BookingID)+"::uuid")
+	}
+	if filter.ShowID != "" {
+		conditions = append(conditions, "t.booking_id IN (SELECT booking_id FROM bookings WHERE show_id = "+arg(filter.ShowID)+"::uuid)")
+	}
+
+	if filter.Cursor != "" {
+		c, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return entities.TicketPage{}, err
+		}
+		if c.Sort != filter.Sort {
+			return entities.TicketPage{}, fmt.Errorf("%w: cursor was created for a different sort", ErrInvalidCursor)
+		}
+
+		op := ">"
+		if desc {
+			op = "<"
+		}
+		conditions = append(conditions, fmt.Sprintf(
+			"(%s, t.ticket_id) %s (%s::%s, %s::uuid)",
+			sortCol.expr, op, arg(c.Key), sortCol.cast, arg(c.TicketID),
+		))
+	}
+
+	direction := "ASC"
+	if desc {
+		direction = "DESC"
+	}
+
+	query := ticketListColumns + ", (" + sortCol.expr + ")::text\nFROM tickets t\n"
+	if len(conditions) > 0 {
+		query += "WHERE " + strings.Join(conditions, " AND ") + "\n"
+	}
+	query += fmt.Sprintf("ORDER BY %s %s, t.ticket_id %s\nLIMIT %s", sortCol.expr, direction, direction, arg(limit+1))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return entities.TicketPage{}, err
+	}
+
+	defer rows.Close()
+
+	page := entities.TicketPage{Items: []entities.TicketList{}}
+	var sortKey, lastKey string
+
+	for rows.Next() {
+		var ticket entities.TicketList
+
+		if err := rows.Scan(
+			&ticket.TicketID,
+			&ticket.Price.Amount,
+			&ticket.Price.Currency,
+			&ticket.CustomerEmail,
+			&ticket.Status,
+			&ticket.BookingID,
+			&ticket.ConfirmedAt,
+			&ticket.PrintedAt,
+			&ticket.ReceiptIssuedAt,
+			&ticket.CanceledAt,
+			&ticket.RefundedAt,
+			&sortKey,
+		); err != nil {
+			return entities.TicketPage{}, err
+		}
+
+		if len(page.Items) == limit {
+			last := page.Items[len(page.Items)-1]
+			page.NextCursor = cursor{Sort: filter.Sort, Key: lastKey, TicketID: last.TicketID}.encode()
+			break
+		}
+
+		lastKey = sortKey
+		page.Items = append(page.Items, ticket)
+	}
+
+	if err := rows.Close(); err != nil {
+		return entities.TicketPage{}, err
+	}
+
+	if err := rows.Err(); err != nil {
+		return entities.TicketPage{}, err
+	}
+
+	return page, nil
+}
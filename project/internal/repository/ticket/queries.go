@@ -3,26 +3,83 @@ package ticket
 const (
 	saveTicket = `
 INSERT INTO tickets (
-  ticket_id, price_amount, price_currency, customer_email
+  ticket_id, price_amount, price_currency, customer_email, status, confirmed_at, booking_id
 ) VALUES (
-  $1, $2, $3, $4
+  $1, $2, $3, $4, 'confirmed', $5, NULLIF($6, '')::uuid
 )
-ON CONFLICT DO NOTHING
+ON CONFLICT (ticket_id) DO UPDATE SET
+  confirmed_at = COALESCE(tickets.confirmed_at, excluded.confirmed_at),
+  booking_id = COALESCE(tickets.booking_id, excluded.booking_id)
 `
-	deleteTicket = `
-DELETE
-FROM tickets
+
+	// cancelTicket creates the ticket if cancellation arrived before confirmation,
+	// so the confirmation can't bring it back.
+	cancelTicket = `
+INSERT INTO tickets (
+  ticket_id, price_amount, price_currency, customer_email, status, canceled_at, booking_id
+) VALUES (
+  $1, $2, $3, $4, 'canceled', $5, NULLIF($7, '')::uuid
+)
+ON CONFLICT (ticket_id) DO UPDATE SET
+  status = 'canceled',
+  canceled_at = COALESCE(tickets.canceled_at, excluded.canceled_at)
+WHERE tickets.status = ANY($6)
+`
+
+	refundTicket = `
+UPDATE tickets
+SET status = 'refunded', refunded_at = COALESCE(refunded_at, $2)
+WHERE ticket_id = $1 AND status = ANY($3)
+`
+
+	markTicketPrinted = `
+UPDATE tickets
+SET printed_at = COALESCE(printed_at, $2)
+WHERE ticket_id = $1
+`
+
+	markReceiptIssued = `
+UPDATE tickets
+SET receipt_issued_at = COALESCE(receipt_issued_at, $2)
 WHERE ticket_id = $1
 `
 
 	getTicketByID = `
-SELECT ticket_id, price_amount, price_currency, customer_email
+SELECT ticket_id, price_amount, price_currency, customer_email, status
 FROM tickets
 WHERE ticket_id = $1 LIMIT 1
 `
 
-	ticketList = `
-SELECT ticket_id, price_amount, price_currency, customer_email
+	// ticketListColumns is followed by the sort key of the row, used to build the next cursor.
+	ticketListColumns = `
+SELECT
+  t.ticket_id, t.price_amount, t.price_currency, t.customer_email, t.status,
+  COALESCE(t.booking_id::text, ''),
+  t.confirmed_at, t.printed_at, t.receipt_issued_at, t.canceled_at, t.refunded_at
+`
+
+	// checkInTicket only checks in confirmed tickets that weren't scanned yet,
+	// a concurrent scan of the same ticket waits for the row lock and then updates nothing.
+	checkInTicket = `
+UPDATE tickets t
+SET checked_in_at = $2
+WHERE t.ticket_id = $1 AND t.status = 'confirmed' AND t.checked_in_at IS NULL
+RETURNING
+  t.ticket_id,
+  COALESCE(t.booking_id::text, '') AS booking_id,
+  COALESCE((SELECT b.show_id::text FROM bookings b WHERE b.booking_id = t.booking_id), '') AS show_id,
+  t.checked_in_at
+`
+
+	ticketCheckInState = `
+SELECT status, checked_in_at IS NOT NULL
 FROM tickets
+WHERE ticket_id = $1
+`
+
+	insertStatusChange = `
+INSERT INTO ticket_status_changes (idempotency_key, ticket_id, status)
+VALUES ($1, $2, $3)
+ON CONFLICT (idempotency_key, ticket_id) DO NOTHING
 `
 )
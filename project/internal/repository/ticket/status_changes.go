@@ -0,0 +1,72 @@
+package ticket
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"tickets/internal/broker/outbox"
+	"tickets/internal/entities"
+	"tickets/internal/repository/transaction"
+)
+
+// RecordStatusChanges stores the incoming ticket status changes and publishes their events
+// in the same transaction, the outbox forwarder delivers them afterwards.
+// Changes already recorded with the same idempotency key are skipped, so retried requests don't publish twice.
+func (r *Repo) RecordStatusChanges(ctx context.Context, idempotencyKey string, tickets []entities.Ticket) error {
+	return transaction.UpdateInTx(
+		ctx,
+		r.db,
+		sql.LevelReadCommitted,
+		func(ctx context.Context, tx *sqlx.Tx) error {
+			var events []any
+			for _, ticket := range tickets {
+				res, err := tx.ExecContext(ctx, insertStatusChange, idempotencyKey, ticket.TicketID, ticket.Status)
+				if err != nil {
+					return fmt.Errorf("could not record status change of ticket %s: %w", ticket.TicketID, err)
+				}
+
+				recorded, err := res.RowsAffected()
+				if err != nil {
+					return err
+				}
+				if recorded == 0 {
+					continue
+				}
+
+				events = append(events, statusChangeEvents(idempotencyKey, ticket)...)
+			}
+
+			return outbox.PublishInTx(ctx, tx.Tx, events...)
+		},
+	)
+}
+
+func statusChangeEvents(idempotencyKey string, ticket entities.Ticket) []any {
+	header := entities.NewEventHeader(idempotencyKey + ticket.TicketID)
+
+	switch ticket.Status {
+	case entities.TicketStatusConfirmed:
+		return []any{
+			entities.TicketBookingConfirmed{
+				Header:        header,
+				TicketID:      ticket.TicketID,
+				CustomerEmail: ticket.CustomerEmail,
+				Price:         ticket.Price,
+				BookingID:     ticket.BookingID,
+			},
+		}
+	case entities.TicketStatusCanceled:
+		return []any{
+			entities.TicketBookingCanceled{
+				Header:        header,
+				TicketID:      ticket.TicketID,
+				CustomerEmail: ticket.CustomerEmail,
+				Price:         ticket.Price,
+				BookingID:     ticket.BookingID,
+			},
+		}
+	}
+
+	return nil
+}
@@ -2,10 +2,17 @@ package ticket
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"tickets/internal/entities"
+	"time"
 )
 
+var ErrInvalidTransition = errors.New("invalid ticket status transition")
+
 type Repo struct {
 	db *sqlx.DB
 }
@@ -22,63 +29,110 @@ func (r *Repo) SaveTicket(ctx context.Context, confirmed entities.TicketBookingC
 		confirmed.Price.Amount,
 		confirmed.Price.Currency,
 		confirmed.CustomerEmail,
+		confirmed.Header.OccurredAt(),
+		confirmed.BookingID,
 	)
 
 	return err
 }
 
-func (r *Repo) DeleteTicket(ctx context.Context, ticketID string) error {
-	_, err := r.db.ExecContext(ctx, deleteTicket, ticketID)
+func (r *Repo) CancelTicket(ctx context.Context, canceled entities.TicketBookingCanceled) error {
+	_, err := r.db.ExecContext(ctx, cancelTicket,
+		canceled.TicketID,
+		canceled.Price.Amount,
+		canceled.Price.Currency,
+		canceled.CustomerEmail,
+		canceled.Header.OccurredAt(),
+		pq.Array(entities.TicketStatusesAllowedBefore(entities.TicketStatusCanceled)),
+		canceled.BookingID,
+	)
+
 	return err
 }
 
-func (r *Repo) GetByID(ctx context.Context, ticketID string) (entities.Ticket, error) {
-	row := r.db.QueryRowContext(ctx, getTicketByID, ticketID)
-
-	var ticket entities.Ticket
-
-	err := row.Scan(
-		&ticket.TicketID,
-		&ticket.Price.Amount,
-		&ticket.Price.Currency,
-		&ticket.CustomerEmail,
+func (r *Repo) RefundTicket(ctx context.Context, ticketID string, refundedAt time.Time) error {
+	res, err := r.db.ExecContext(ctx, refundTicket,
+		ticketID,
+		refundedAt,
+		pq.Array(entities.TicketStatusesAllowedBefore(entities.TicketStatusRefunded)),
 	)
+	if err != nil {
+		return err
+	}
 
-	return ticket, err
+	return r.checkTransition(ctx, res, ticketID, entities.TicketStatusRefunded)
 }
 
-func (r *Repo) TicketList(ctx context.Context) ([]entities.TicketList, error) {
-	rows, err := r.db.QueryContext(ctx, ticketList)
+func (r *Repo) MarkTicketPrinted(ctx context.Context, ticketID string, printedAt time.Time) error {
+	res, err := r.db.ExecContext(ctx, markTicketPrinted, ticketID, printedAt)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	defer rows.Close()
+	return checkTicketUpdated(res, ticketID)
+}
 
-	var tickets []entities.TicketList
+func (r *Repo) MarkReceiptIssued(ctx context.Context, ticketID string, issuedAt time.Time) error {
+	res, err := r.db.ExecContext(ctx, markReceiptIssued, ticketID, issuedAt)
+	if err != nil {
+		return err
+	}
 
-	for rows.Next() {
-		var ticket entities.TicketList
+	return checkTicketUpdated(res, ticketID)
+}
 
-		if err := rows.Scan(
-			&ticket.TicketID,
-			&ticket.Price.Amount,
-			&ticket.Price.Currency,
-			&ticket.CustomerEmail,
-		); err != nil {
-			return nil, err
-		}
+// checkTransition tells apart a ticket that is already in the target status,
+// which is fine on redelivery, from a missing ticket or a forbidden transition.
+func (r *Repo) checkTransition(ctx context.Context, res sql.Result, ticketID, status string) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected > 0 {
+		return nil
+	}
 
-		tickets = append(tickets, ticket)
+	ticket, err := r.GetByID(ctx, ticketID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("ticket %s not found", ticketID)
+	}
+	if err != nil {
+		return err
 	}
 
-	if err := rows.Close(); err != nil {
-		return nil, err
+	if ticket.Status == status {
+		return nil
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, err
+	return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, ticket.Status, status)
+}
+
+// checkTicketUpdated returns an error when the ticket isn't saved yet,
+// so the message is retried once TicketBookingConfirmed is processed.
+func checkTicketUpdated(res sql.Result, ticketID string) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
 	}
+	if affected == 0 {
+		return fmt.Errorf("ticket %s not found", ticketID)
+	}
+
+	return nil
+}
+
+func (r *Repo) GetByID(ctx context.Context, ticketID string) (entities.Ticket, error) {
+	row := r.db.QueryRowContext(ctx, getTicketByID, ticketID)
+
+	var ticket entities.Ticket
 
-	return tickets, nil
+	err := row.Scan(
+		&ticket.TicketID,
+		&ticket.Price.Amount,
+		&ticket.Price.Currency,
+		&ticket.CustomerEmail,
+		&ticket.Status,
+	)
+
+	return ticket, err
 }
@@ -0,0 +1,95 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"math/rand"
+	"tickets/internal/tracing"
+	"time"
+)
+
+const maxRetries = 10
+
+func UpdateInTx(
+	ctx context.Context,
+	db *sqlx.DB,
+	isolation sql.IsolationLevel,
+	fn func(ctx context.Context, tx *sqlx.Tx) error,
+) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "sql transaction", trace.WithAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.isolation_level", isolation.String()),
+	))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	tx, err := db.BeginTxx(ctx, &sql.TxOptions{Isolation: isolation})
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				err = errors.Join(err, rollbackErr)
+			}
+			return
+		}
+
+		err = tx.Commit()
+	}()
+
+	return fn(ctx, tx)
+}
+
+// UpdateInTxWithRetry works like UpdateInTx, but runs the whole transaction again
+// when Postgres aborts it because of a serialization failure or a deadlock.
+func UpdateInTxWithRetry(
+	ctx context.Context,
+	db *sqlx.DB,
+	isolation sql.IsolationLevel,
+	fn func(ctx context.Context, tx *sqlx.Tx) error,
+) error {
+	for attempt := 1; ; attempt++ {
+		err := UpdateInTx(ctx, db, isolation, fn)
+		if err == nil || !isRetryable(err) || attempt >= maxRetries {
+			return err
+		}
+
+		// jitter spreads retries of transactions that conflicted with each other
+		backoff := time.Duration(attempt)*10*time.Millisecond + time.Duration(rand.Intn(10))*time.Millisecond
+
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(backoff):
+		}
+	}
+}
+
+func isRetryable(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+
+	switch pqErr.Code {
+	case "40001", // serialization_failure
+		"40P01": // deadlock_detected
+		return true
+	default:
+		return false
+	}
+}
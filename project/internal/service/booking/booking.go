@@ -19,3 +19,7 @@ func (s *Service) BookTicket(ctx context.Context, booking entities.Booking) (str
 	booking.BookingID = uuid.New()
 	return s.repo.BookTicket(ctx, booking)
 }
+
+func (s *Service) CancelBooking(ctx context.Context, bookingID uuid.UUID) error {
+	return s.repo.CancelBooking(ctx, bookingID)
+}
@@ -1,6 +1,7 @@
 package files
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"github.com/ThreeDotsLabs/go-event-driven/common/clients"
@@ -17,28 +18,21 @@ func NewClient(client *clients.Clients) *Client {
 	return &Client{client: client}
 }
 
-func (c *Client) StoreTicketContent(ctx context.Context, ticket entities.TicketBookingConfirmed) error {
-	ticketContent := fmt.Sprintf(`<html>
-			<head>
-				<title>Ticket</title>
-			</head>
-			<body>
-				<h1>Ticket %s</h1>
-				<p>Price: %s %s</p>	
-			</body>
-		</html>`, ticket.TicketID, ticket.Price.Amount, ticket.Price.Currency)
-
-	fileName := fmt.Sprintf("%s-ticket.html", ticket.TicketID)
-
-	response, err := c.client.Files.PutFilesFileIdContentWithTextBodyWithResponse(ctx, fileName, ticketContent)
+// StoreTicketContent uploads a rendered ticket file.
+// A file that already exists is fine, it was stored by an earlier attempt.
+func (c *Client) StoreTicketContent(ctx context.Context, file entities.TicketFile) error {
+	response, err := c.client.Files.PutFilesFileIdContentWithBodyWithResponse(ctx, file.Name, file.ContentType, bytes.NewReader(file.Content))
 	if err != nil {
 		return err
 	}
 
-	if response.StatusCode() == http.StatusConflict {
-		log.FromContext(ctx).Info("file %s already exists", ticket.TicketID)
+	switch response.StatusCode() {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
 		return nil
+	case http.StatusConflict:
+		log.FromContext(ctx).Infof("file %s already exists", file.Name)
+		return nil
+	default:
+		return fmt.Errorf("unexpected status code %d while storing ticket file %s", response.StatusCode(), file.Name)
 	}
-
-	return nil
 }
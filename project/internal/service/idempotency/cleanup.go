@@ -0,0 +1,50 @@
+package idempotency
+
+import (
+	"context"
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+	"tickets/internal/repository"
+	"time"
+)
+
+const cleanupInterval = time.Hour
+
+// KeyCleaner removes Idempotency-Key responses older than the retention window.
+// Keys left in progress by requests that stopped halfway are removed the same way,
+// after that the client can use the key again.
+type KeyCleaner struct {
+	repo      repository.Idempotency
+	retention time.Duration
+}
+
+func NewKeyCleaner(repo repository.Idempotency, retention time.Duration) *KeyCleaner {
+	if repo == nil {
+		panic("missing repo")
+	}
+	if retention <= 0 {
+		panic("retention must be positive")
+	}
+
+	return &KeyCleaner{repo: repo, retention: retention}
+}
+
+// Run removes expired keys until ctx is done.
+func (c *KeyCleaner) Run(ctx context.Context) error {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := c.repo.DeleteKeysBefore(ctx, time.Now().Add(-c.retention))
+		if err != nil {
+			log.FromContext(ctx).WithError(err).Error("Failed to delete expired idempotency keys")
+		} else if deleted > 0 {
+			log.FromContext(ctx).WithField("deleted", deleted).Info("Deleted expired idempotency keys")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package idempotency
+
+import (
+	"context"
+	"tickets/internal/entities"
+	"tickets/internal/repository"
+)
+
+type Service struct {
+	repo repository.Idempotency
+}
+
+func NewService(repo repository.Idempotency) *Service {
+	return &Service{repo: repo}
+}
+
+func (s *Service) ReserveIdempotencyKey(ctx context.Context, endpoint, key, requestHash string) (entities.IdempotentResponse, bool, error) {
+	return s.repo.ReserveKey(ctx, endpoint, key, requestHash)
+}
+
+func (s *Service) SaveIdempotentResponse(ctx context.Context, endpoint, key string, statusCode int, body []byte) error {
+	return s.repo.SaveResponse(ctx, endpoint, key, statusCode, body)
+}
+
+func (s *Service) ReleaseIdempotencyKey(ctx context.Context, endpoint, key string) error {
+	return s.repo.ReleaseKey(ctx, endpoint, key)
+}
@@ -0,0 +1,23 @@
+package ops
+
+import (
+	"context"
+	"tickets/internal/entities"
+	"tickets/internal/repository"
+)
+
+type Service struct {
+	repo repository.Ops
+}
+
+func NewService(repo repository.Ops) *Service {
+	return &Service{repo: repo}
+}
+
+func (s *Service) ReservationList(ctx context.Context, filter entities.OpsBookingFilter) (entities.OpsBookingPage, error) {
+	return s.repo.ReservationList(ctx, filter)
+}
+
+func (s *Service) ReservationReadModel(ctx context.Context, bookingID string) (entities.OpsBooking, error) {
+	return s.repo.ReservationReadModel(ctx, bookingID)
+}
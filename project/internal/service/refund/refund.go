@@ -0,0 +1,40 @@
+package refund
+
+import (
+	"context"
+	"tickets/internal/entities"
+	"tickets/internal/repository"
+	"time"
+)
+
+type Service struct {
+	repo repository.Refund
+}
+
+func NewService(repo repository.Refund) *Service {
+	return &Service{repo: repo}
+}
+
+func (s *Service) StartRefund(ctx context.Context, ticketID string, idempotencyKey string) (entities.Refund, error) {
+	return s.repo.StartRefund(ctx, ticketID, idempotencyKey)
+}
+
+func (s *Service) MarkReceiptVoided(ctx context.Context, ticketID string) error {
+	return s.repo.MarkReceiptVoided(ctx, ticketID)
+}
+
+func (s *Service) CompleteRefund(ctx context.Context, ticketID string) error {
+	return s.repo.CompleteRefund(ctx, ticketID)
+}
+
+func (s *Service) RecordFailure(ctx context.Context, ticketID string, reason string, maxAttempts int, retryDelay time.Duration) (entities.Refund, error) {
+	return s.repo.RecordFailure(ctx, ticketID, reason, maxAttempts, retryDelay)
+}
+
+func (s *Service) ResetFailedRefund(ctx context.Context, ticketID string) (entities.Refund, error) {
+	return s.repo.ResetFailedRefund(ctx, ticketID)
+}
+
+func (s *Service) RefundList(ctx context.Context, status string, onlyFailed bool) ([]entities.Refund, error) {
+	return s.repo.RefundList(ctx, status, onlyFailed)
+}
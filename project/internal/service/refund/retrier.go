@@ -0,0 +1,80 @@
+package refund
+
+import (
+	"context"
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+	"tickets/internal/entities"
+	"tickets/internal/repository"
+	"time"
+)
+
+type CommandSender interface {
+	Send(ctx context.Context, cmd any) error
+}
+
+// Retrier sends RefundTicket again for refunds whose next attempt is due.
+// The command keeps the idempotency key of the refund, so it continues from the step that failed.
+type Retrier struct {
+	repo          repository.Refund
+	commandSender CommandSender
+	interval      time.Duration
+}
+
+func NewRetrier(repo repository.Refund, commandSender CommandSender, interval time.Duration) *Retrier {
+	if repo == nil {
+		panic("missing repo")
+	}
+	if commandSender == nil {
+		panic("missing commandSender")
+	}
+	if interval <= 0 {
+		panic("interval must be positive")
+	}
+
+	return &Retrier{repo: repo, commandSender: commandSender, interval: interval}
+}
+
+// Run retries due refunds until ctx is done.
+func (r *Retrier) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.retryDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Retrier) retryDue(ctx context.Context) {
+	refunds, err := r.repo.RefundsToRetry(ctx)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("Failed to get refunds to retry")
+		return
+	}
+
+	for _, refund := range refunds {
+		logger := log.FromContext(ctx).WithField("ticket_id", refund.TicketID)
+
+		err := r.commandSender.Send(ctx, entities.RefundTicket{
+			Header:   entities.NewCommandHeader(refund.IdempotencyKey),
+			TicketID: refund.TicketID,
+		})
+		if err != nil {
+			logger.WithError(err).Error("Failed to send refund retry")
+			continue
+		}
+
+		// sending twice is fine, the refund continues from its stored state
+		if err := r.repo.ClearRetry(ctx, refund.TicketID); err != nil {
+			logger.WithError(err).Error("Failed to clear refund retry")
+			continue
+		}
+
+		logger.WithField("attempts", refund.Attempts).Info("Refund retry sent")
+	}
+}
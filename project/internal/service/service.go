@@ -6,8 +6,12 @@ import (
 	"tickets/internal/entities"
 	"tickets/internal/repository"
 	"tickets/internal/service/booking"
+	"tickets/internal/service/idempotency"
+	"tickets/internal/service/ops"
+	"tickets/internal/service/refund"
 	"tickets/internal/service/show"
 	"tickets/internal/service/ticket"
+	"time"
 )
 
 type ReceiptsClient interface {
@@ -20,7 +24,7 @@ type SpreadsheetsClient interface {
 }
 
 type FilesClient interface {
-	StoreTicketContent(ctx context.Context, ticket entities.TicketBookingConfirmed) error
+	StoreTicketContent(ctx context.Context, file entities.TicketFile) error
 }
 
 type DeadNationClient interface {
@@ -29,32 +33,80 @@ type DeadNationClient interface {
 
 type Ticket interface {
 	SaveTicket(ctx context.Context, ticket entities.TicketBookingConfirmed) error
-	DeleteTicket(ctx context.Context, ticketID string) error
-	TicketList(ctx context.Context) ([]entities.TicketList, error)
+	CancelTicket(ctx context.Context, canceled entities.TicketBookingCanceled) error
+	TicketList(ctx context.Context, filter entities.TicketFilter) (entities.TicketPage, error)
+	TicketByID(ctx context.Context, ticketID string) (entities.Ticket, error)
+	MarkTicketRefunded(ctx context.Context, ticketID string, refundedAt time.Time) error
+	MarkTicketPrinted(ctx context.Context, ticketID string, printedAt time.Time) error
+	MarkReceiptIssued(ctx context.Context, ticketID string, issuedAt time.Time) error
+	UpdateTicketStatuses(ctx context.Context, idempotencyKey string, tickets []entities.Ticket) error
+	CheckInTicket(ctx context.Context, ticketID, token string) (entities.CheckIn, error)
 }
 
 type Show interface {
 	NewShow(ctx context.Context, show entities.Show) (string, error)
 	ShowByID(ctx context.Context, showId uuid.UUID) (entities.Show, error)
+	ShowOfBooking(ctx context.Context, bookingID uuid.UUID) (*entities.Show, error)
+	ShowList(ctx context.Context) ([]entities.Show, error)
+	UpdateShow(ctx context.Context, showID uuid.UUID, update entities.ShowUpdate) (entities.Show, error)
+	CancelShow(ctx context.Context, showID uuid.UUID) error
+	ShowAvailability(ctx context.Context, showID uuid.UUID) (entities.ShowAvailability, error)
+	ShowCheckIns(ctx context.Context, showID uuid.UUID) (entities.ShowCheckIns, error)
 }
 
 type Booking interface {
 	BookTicket(ctx context.Context, booking entities.Booking) (string, error)
+	CancelBooking(ctx context.Context, bookingID uuid.UUID) error
+}
+
+type Ops interface {
+	ReservationList(ctx context.Context, filter entities.OpsBookingFilter) (entities.OpsBookingPage, error)
+	ReservationReadModel(ctx context.Context, bookingID string) (entities.OpsBooking, error)
 }
 
 type PaymentClient interface {
 	PutRefundsWithResponse(ctx context.Context, command entities.PaymentRefund) error
 }
 
+type Refund interface {
+	StartRefund(ctx context.Context, ticketID string, idempotencyKey string) (entities.Refund, error)
+	MarkReceiptVoided(ctx context.Context, ticketID string) error
+	CompleteRefund(ctx context.Context, ticketID string) error
+	RecordFailure(ctx context.Context, ticketID string, reason string, maxAttempts int, retryDelay time.Duration) (entities.Refund, error)
+	ResetFailedRefund(ctx context.Context, ticketID string) (entities.Refund, error)
+	RefundList(ctx context.Context, status string, onlyFailed bool) ([]entities.Refund, error)
+}
+
+type TicketTokenVerifier interface {
+	Verify(token string) (string, error)
+}
+
+type DeadLetterQueue interface {
+	DeadLetters(ctx context.Context, limit int64) ([]entities.DeadLetter, error)
+	DeadLetterByID(ctx context.Context, id string) (entities.DeadLetter, error)
+	RequeueDeadLetter(ctx context.Context, id string) error
+	DeleteDeadLetter(ctx context.Context, id string) error
+}
+
+type Idempotency interface {
+	ReserveIdempotencyKey(ctx context.Context, endpoint, key, requestHash string) (entities.IdempotentResponse, bool, error)
+	SaveIdempotentResponse(ctx context.Context, endpoint, key string, statusCode int, body []byte) error
+	ReleaseIdempotencyKey(ctx context.Context, endpoint, key string) error
+}
+
 type Service struct {
 	ReceiptsClient
 	SpreadsheetsClient
 	FilesClient
 	DeadNationClient
 	PaymentClient
+	DeadLetterQueue
 	Ticket
 	Show
 	Booking
+	Ops
+	Refund
+	Idempotency
 }
 
 func NewService(receiptsClient ReceiptsClient,
@@ -62,6 +114,8 @@ func NewService(receiptsClient ReceiptsClient,
 	filesClient FilesClient,
 	deadNationClient DeadNationClient,
 	paymentClient PaymentClient,
+	deadLetterQueue DeadLetterQueue,
+	ticketTokens TicketTokenVerifier,
 	repo *repository.Repository) *Service {
 
 	return &Service{
@@ -70,9 +124,13 @@ func NewService(receiptsClient ReceiptsClient,
 		FilesClient:        filesClient,
 		DeadNationClient:   deadNationClient,
 		PaymentClient:      paymentClient,
-		Ticket:             ticket.NewService(repo.Ticket),
-		Show:               show.NewService(repo.Show),
+		DeadLetterQueue:    deadLetterQueue,
+		Ticket:             ticket.NewService(repo.Ticket, ticketTokens),
+		Show:               show.NewService(repo.Show, repo.ShowAvailability, repo.ShowCheckIns),
 		Booking:            booking.NewService(repo.Booking),
+		Ops:                ops.NewService(repo.Ops),
+		Refund:             refund.NewService(repo.Refund),
+		Idempotency:        idempotency.NewService(repo.Idempotency),
 	}
 
 }
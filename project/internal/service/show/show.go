@@ -2,17 +2,21 @@ package show
 
 import (
 	"context"
+	"errors"
 	"github.com/google/uuid"
 	"tickets/internal/entities"
 	"tickets/internal/repository"
+	showRepo "tickets/internal/repository/show"
 )
 
 type Service struct {
-	repo repository.Show
+	repo             repository.Show
+	availabilityRepo repository.ShowAvailability
+	checkInsRepo     repository.ShowCheckIns
 }
 
-func NewService(repo repository.Show) *Service {
-	return &Service{repo: repo}
+func NewService(repo repository.Show, availabilityRepo repository.ShowAvailability, checkInsRepo repository.ShowCheckIns) *Service {
+	return &Service{repo: repo, availabilityRepo: availabilityRepo, checkInsRepo: checkInsRepo}
 }
 
 func (s *Service) NewShow(ctx context.Context, show entities.Show) (string, error) {
@@ -23,3 +27,36 @@ func (s *Service) NewShow(ctx context.Context, show entities.Show) (string, erro
 func (s *Service) ShowByID(ctx context.Context, showId uuid.UUID) (entities.Show, error) {
 	return s.repo.ShowByID(ctx, showId)
 }
+
+// ShowOfBooking returns nil if the booking isn't ours, e.g. it was made in Dead Nation.
+func (s *Service) ShowOfBooking(ctx context.Context, bookingID uuid.UUID) (*entities.Show, error) {
+	show, err := s.repo.ShowByBookingID(ctx, bookingID)
+	if errors.Is(err, showRepo.ErrShowNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &show, nil
+}
+
+func (s *Service) ShowList(ctx context.Context) ([]entities.Show, error) {
+	return s.repo.ShowList(ctx)
+}
+
+func (s *Service) UpdateShow(ctx context.Context, showID uuid.UUID, update entities.ShowUpdate) (entities.Show, error) {
+	return s.repo.UpdateShow(ctx, showID, update)
+}
+
+func (s *Service) CancelShow(ctx context.Context, showID uuid.UUID) error {
+	return s.repo.CancelShow(ctx, showID)
+}
+
+func (s *Service) ShowAvailability(ctx context.Context, showID uuid.UUID) (entities.ShowAvailability, error) {
+	return s.availabilityRepo.ShowAvailability(ctx, showID)
+}
+
+func (s *Service) ShowCheckIns(ctx context.Context, showID uuid.UUID) (entities.ShowCheckIns, error) {
+	return s.checkInsRepo.ShowCheckIns(ctx, showID)
+}
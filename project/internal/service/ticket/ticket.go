@@ -4,24 +4,64 @@ import (
 	"context"
 	"tickets/internal/entities"
 	"tickets/internal/repository"
+	"tickets/internal/tickettoken"
+	"time"
 )
 
+type tokenVerifier interface {
+	Verify(token string) (string, error)
+}
+
 type Service struct {
-	repo repository.Ticket
+	repo   repository.Ticket
+	tokens tokenVerifier
 }
 
-func NewService(repo repository.Ticket) *Service {
-	return &Service{repo: repo}
+func NewService(repo repository.Ticket, tokens tokenVerifier) *Service {
+	return &Service{repo: repo, tokens: tokens}
 }
 
 func (s *Service) SaveTicket(ctx context.Context, ticket entities.TicketBookingConfirmed) error {
 	return s.repo.SaveTicket(ctx, ticket)
 }
 
-func (s *Service) DeleteTicket(ctx context.Context, ticketID string) error {
-	return s.repo.DeleteTicket(ctx, ticketID)
+func (s *Service) CancelTicket(ctx context.Context, canceled entities.TicketBookingCanceled) error {
+	return s.repo.CancelTicket(ctx, canceled)
+}
+
+func (s *Service) TicketList(ctx context.Context, filter entities.TicketFilter) (entities.TicketPage, error) {
+	return s.repo.TicketList(ctx, filter)
+}
+
+func (s *Service) TicketByID(ctx context.Context, ticketID string) (entities.Ticket, error) {
+	return s.repo.GetByID(ctx, ticketID)
+}
+
+func (s *Service) MarkTicketRefunded(ctx context.Context, ticketID string, refundedAt time.Time) error {
+	return s.repo.RefundTicket(ctx, ticketID, refundedAt)
 }
 
-func (s *Service) TicketList(ctx context.Context) ([]entities.TicketList, error) {
-	return s.repo.TicketList(ctx)
+func (s *Service) MarkTicketPrinted(ctx context.Context, ticketID string, printedAt time.Time) error {
+	return s.repo.MarkTicketPrinted(ctx, ticketID, printedAt)
+}
+
+func (s *Service) MarkReceiptIssued(ctx context.Context, ticketID string, issuedAt time.Time) error {
+	return s.repo.MarkReceiptIssued(ctx, ticketID, issuedAt)
+}
+
+func (s *Service) UpdateTicketStatuses(ctx context.Context, idempotencyKey string, tickets []entities.Ticket) error {
+	return s.repo.RecordStatusChanges(ctx, idempotencyKey, tickets)
+}
+
+// CheckInTicket checks in the ticket if the token scanned at the door was signed for it.
+func (s *Service) CheckInTicket(ctx context.Context, ticketID, token string) (entities.CheckIn, error) {
+	tokenTicketID, err := s.tokens.Verify(token)
+	if err != nil {
+		return entities.CheckIn{}, err
+	}
+	if tokenTicketID != ticketID {
+		return entities.CheckIn{}, tickettoken.ErrInvalidToken
+	}
+
+	return s.repo.CheckInTicket(ctx, ticketID, time.Now().UTC())
 }
@@ -0,0 +1,54 @@
+package tickettoken
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+var ErrInvalidToken = errors.New("invalid ticket token")
+
+// Signer signs ticket IDs with HMAC-SHA256, the token is printed on the ticket as a QR code.
+type Signer struct {
+	secret []byte
+}
+
+func NewSigner(secret string) *Signer {
+	if secret == "" {
+		panic("missing secret")
+	}
+
+	return &Signer{secret: []byte(secret)}
+}
+
+// Sign returns the token of the ticket in the form "<ticket id>.<signature>".
+func (s *Signer) Sign(ticketID string) string {
+	return ticketID + "." + base64.RawURLEncoding.EncodeToString(s.signature(ticketID))
+}
+
+// Verify returns the ticket ID of a token signed with the same secret.
+func (s *Signer) Verify(token string) (string, error) {
+	ticketID, encoded, ok := strings.Cut(token, ".")
+	if !ok || ticketID == "" {
+		return "", ErrInvalidToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", ErrInvalidToken
+	}
+
+	if !hmac.Equal(signature, s.signature(ticketID)) {
+		return "", ErrInvalidToken
+	}
+
+	return ticketID, nil
+}
+
+func (s *Signer) signature(ticketID string) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(ticketID))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,88 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"os"
+	"tickets/internal/config"
+)
+
+const serviceName = "tickets"
+
+func Tracer() trace.Tracer {
+	return otel.Tracer(serviceName)
+}
+
+// NewExporter creates the configured span exporter:
+//   - "otlp" sends spans over OTLP/HTTP, configured with the standard OTEL_EXPORTER_OTLP_* variables,
+//   - "file" writes spans as JSON lines to the traces file,
+//   - "stdout" prints spans,
+//   - empty or "none" disables exporting, spans are still created so trace context is propagated.
+func NewExporter(ctx context.Context, cfg config.Tracing) (sdktrace.SpanExporter, error) {
+	switch exporter := cfg.Exporter; exporter {
+	case "", "none":
+		return nil, nil
+	case "otlp":
+		return otlptracehttp.New(ctx)
+	case "file":
+		f, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("could not open traces file: %w", err)
+		}
+
+		return newFileExporter(f)
+	case "stdout":
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	default:
+		return nil, fmt.Errorf("unknown traces exporter %q", exporter)
+	}
+}
+
+// InitTracerProvider sets the global tracer provider and the W3C trace context propagator.
+// Tests can pass tracetest.InMemoryExporter and call ForceFlush before reading the spans.
+func InitTracerProvider(exporter sdktrace.SpanExporter) *sdktrace.TracerProvider {
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))),
+	}
+	if exporter != nil {
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+
+	tp := sdktrace.NewTracerProvider(opts...)
+
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return tp
+}
+
+type fileExporter struct {
+	sdktrace.SpanExporter
+	file io.Closer
+}
+
+func newFileExporter(f *os.File) (sdktrace.SpanExporter, error) {
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(f))
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	return fileExporter{SpanExporter: exporter, file: f}, nil
+}
+
+func (e fileExporter) Shutdown(ctx context.Context) error {
+	if err := e.SpanExporter.Shutdown(ctx); err != nil {
+		return err
+	}
+	return e.file.Close()
+}
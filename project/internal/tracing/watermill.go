@@ -0,0 +1,59 @@
+package tracing
+
+import (
+	"github.com/ThreeDotsLabs/watermill/message"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// PublisherDecorator injects the trace context of the message's context into its metadata.
+// Messages published without a span, like the ones unwrapped by the forwarder,
+// keep the trace context they already carry.
+type PublisherDecorator struct {
+	message.Publisher
+}
+
+func (p PublisherDecorator) Publish(topic string, messages ...*message.Message) error {
+	for _, msg := range messages {
+		if !trace.SpanContextFromContext(msg.Context()).IsValid() {
+			continue
+		}
+
+		otel.GetTextMapPropagator().Inject(msg.Context(), propagation.MapCarrier(msg.Metadata))
+	}
+
+	return p.Publisher.Publish(topic, messages...)
+}
+
+// Middleware continues the trace from the message metadata with a span around the handler.
+func Middleware(h message.HandlerFunc) message.HandlerFunc {
+	return func(msg *message.Message) ([]*message.Message, error) {
+		handlerName := message.HandlerNameFromCtx(msg.Context())
+
+		ctx := otel.GetTextMapPropagator().Extract(msg.Context(), propagation.MapCarrier(msg.Metadata))
+		ctx, span := Tracer().Start(
+			ctx,
+			"handle "+handlerName,
+			trace.WithSpanKind(trace.SpanKindConsumer),
+			trace.WithAttributes(
+				attribute.String("messaging.message.id", msg.UUID),
+				attribute.String("messaging.handler", handlerName),
+				attribute.String("messaging.destination.name", message.SubscribeTopicFromCtx(msg.Context())),
+			),
+		)
+		defer span.End()
+
+		msg.SetContext(ctx)
+
+		msgs, err := h(msg)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+
+		return msgs, err
+	}
+}
@@ -4,27 +4,52 @@ import (
 	"context"
 	"github.com/ThreeDotsLabs/go-event-driven/common/clients"
 	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/redis/go-redis/v9"
 	_ "github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"net/http"
-	"os"
 	"tickets/internal/app"
+	"tickets/internal/config"
+	"tickets/internal/notifications"
 	"tickets/internal/repository"
 	"tickets/internal/service/deadnation"
 	"tickets/internal/service/files"
 	"tickets/internal/service/payment"
 	"tickets/internal/service/receipts"
 	"tickets/internal/service/spreadsheet"
+	"tickets/internal/tracing"
 )
 
 func main() {
+	// config init
+	cfg, err := config.Load()
+	if err != nil {
+		panic(err)
+	}
+
+	// tracing init
+	traceExporter, err := tracing.NewExporter(context.Background(), cfg.Tracing)
+	if err != nil {
+		panic(err)
+	}
+	tracerProvider := tracing.InitTracerProvider(traceExporter)
+	defer func() {
+		_ = tracerProvider.Shutdown(context.Background())
+	}()
+
+	// traced http client, it starts a span for every external call and passes the trace context on
+	httpClient := &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)}
+
 	// client init
-	client, err := clients.NewClients(
-		os.Getenv("GATEWAY_ADDR"),
+	client, err := clients.NewClientsWithHttpClient(
+		cfg.GatewayAddr,
 		func(ctx context.Context, req *http.Request) error {
 			req.Header.Set("Correlation-ID", log.CorrelationIDFromContext(ctx))
 			return nil
 		},
+		httpClient,
 	)
 	if err != nil {
 		panic(err)
@@ -37,16 +62,25 @@ func main() {
 	deadNationClient := deadnation.NewDeadNationClient(client)
 	paymentClient := payment.NewPaymentClient(client)
 
-	db, err := repository.InitDB()
+	mailer, err := notifications.NewMailer(cfg.Notifications)
+	if err != nil {
+		panic(err)
+	}
+
+	db, err := repository.InitDB(cfg.Postgres.URL)
 	if err != nil {
 		panic(err)
 	}
 
 	// redis client init
 	rdb := redis.NewClient(&redis.Options{
-		Addr: os.Getenv("REDIS_ADDR"),
+		Addr: cfg.Redis.Addr,
 	})
 
-	app1 := app.Initialize(receiptsClient, spreadsheetsClient, filesClient, deadNationClient, paymentClient, rdb, db)
+	// app metrics, with the Go runtime and process metrics the default registry would expose
+	metricsRegistry := prometheus.NewRegistry()
+	metricsRegistry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+
+	app1 := app.Initialize(cfg, receiptsClient, spreadsheetsClient, filesClient, deadNationClient, paymentClient, mailer, rdb, db, metricsRegistry)
 	app1.Start()
 }
@@ -0,0 +1,94 @@
+package tests_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"tickets/internal/entities"
+	"time"
+)
+
+func TestCancelBooking_releases_seats(t *testing.T) {
+	env := startApp(t)
+
+	showID := createShow(t, entities.Show{
+		DeadNationID:   uuid.New(),
+		NumberOfTicket: 2,
+		StartTime:      time.Now().Add(24 * time.Hour),
+		Title:          "Cancel booking show",
+		Venue:          "Test venue",
+	})
+
+	booking := entities.Booking{
+		ShowID:          showID,
+		NumberOfTickets: 2,
+		CustomerEmail:   "cancel@example.com",
+	}
+	bookingID := bookTicketsForID(t, booking)
+
+	require.Equal(t, http.StatusBadRequest, bookTickets(t, booking), "show should be sold out")
+
+	// canceling twice cancels the booking once
+	cancelBooking(t, bookingID)
+	cancelBooking(t, bookingID)
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		var canceledAt sql.NullTime
+		err := env.db.GetContext(context.Background(), &canceledAt, "SELECT canceled_at FROM bookings WHERE booking_id = $1", bookingID)
+		if assert.NoError(t, err) {
+			assert.True(t, canceledAt.Valid, "booking should be canceled")
+		}
+
+		var canceledEvents int
+		err = env.db.GetContext(context.Background(), &canceledEvents,
+			"SELECT COUNT(*) FROM events WHERE event_name = 'BookingCanceled' AND payload->>'booking_id' = $1",
+			bookingID,
+		)
+		if assert.NoError(t, err) {
+			assert.Equal(t, 1, canceledEvents)
+		}
+
+		// seats booked in Dead Nation are released by ops
+		assert.Equal(t, 1, rowsPerTicket(env.spreadsheetClient, "bookings-to-release")[bookingID])
+	}, 10*time.Second, 100*time.Millisecond)
+
+	assert.Equal(t, http.StatusCreated, bookTickets(t, booking), "seats of the canceled booking should be available")
+}
+
+func bookTicketsForID(t *testing.T, booking entities.Booking) string {
+	t.Helper()
+
+	payload, err := json.Marshal(booking)
+	require.NoError(t, err)
+
+	resp, err := http.Post("http://localhost:8000/book-tickets", "application/json", bytes.NewBuffer(payload))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var body struct {
+		BookingID string `json:"booking_id"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+
+	return body.BookingID
+}
+
+func cancelBooking(t *testing.T, bookingID string) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodDelete, "http://localhost:8000/bookings/"+bookingID, nil)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+}
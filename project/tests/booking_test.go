@@ -0,0 +1,101 @@
+package tests_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"sync"
+	"testing"
+	"tickets/internal/entities"
+	"time"
+)
+
+func TestBookTicket_concurrent_bookings_do_not_overbook(t *testing.T) {
+	env := startApp(t)
+
+	const (
+		seats    = 50
+		requests = 300
+	)
+
+	showID := createShow(t, entities.Show{
+		DeadNationID:   uuid.New(),
+		NumberOfTicket: seats,
+		StartTime:      time.Now().Add(24 * time.Hour),
+		Title:          "Concurrent show",
+		Venue:          "Test venue",
+	})
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		statuses = map[int]int{}
+	)
+
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			status := bookTickets(t, entities.Booking{
+				ShowID:          showID,
+				NumberOfTickets: 1,
+				CustomerEmail:   fmt.Sprintf("customer-%d@example.com", i),
+			})
+
+			mu.Lock()
+			statuses[status]++
+			mu.Unlock()
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, seats, statuses[http.StatusCreated], "statuses: %v", statuses)
+	assert.Equal(t, requests-seats, statuses[http.StatusBadRequest], "statuses: %v", statuses)
+	for status, count := range statuses {
+		assert.Less(t, status, http.StatusInternalServerError, "%d requests failed with %d", count, status)
+	}
+
+	var booked int
+	err := env.db.Get(&booked, "SELECT COALESCE(SUM(number_of_tickets), 0) FROM bookings WHERE show_id = $1", showID)
+	require.NoError(t, err)
+	assert.Equal(t, seats, booked)
+}
+
+func createShow(t *testing.T, show entities.Show) uuid.UUID {
+	t.Helper()
+
+	payload, err := json.Marshal(show)
+	require.NoError(t, err)
+
+	resp, err := http.Post("http://localhost:8000/shows", "application/json", bytes.NewBuffer(payload))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var body struct {
+		ShowID uuid.UUID `json:"show_id"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+
+	return body.ShowID
+}
+
+func bookTickets(t *testing.T, booking entities.Booking) int {
+	payload, err := json.Marshal(booking)
+	if !assert.NoError(t, err) {
+		return 0
+	}
+
+	resp, err := http.Post("http://localhost:8000/book-tickets", "application/json", bytes.NewBuffer(payload))
+	if !assert.NoError(t, err) {
+		return 0
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode
+}
@@ -0,0 +1,86 @@
+package tests_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"tickets/internal/entities"
+	"tickets/internal/tickettoken"
+	"time"
+)
+
+func TestCheckIn_ticket_is_let_in_once(t *testing.T) {
+	env := startApp(t)
+
+	showID := createShow(t, entities.Show{
+		DeadNationID:   uuid.New(),
+		NumberOfTicket: 10,
+		StartTime:      time.Now().Add(24 * time.Hour),
+		Title:          "Check-in show",
+		Venue:          "Check-in venue",
+	})
+
+	bookingID := uuid.New()
+	_, err := env.db.Exec(
+		`INSERT INTO bookings (booking_id, show_id, number_of_tickets, customer_email) VALUES ($1, $2, 1, $3)`,
+		bookingID, showID, "check-in@example.com",
+	)
+	require.NoError(t, err)
+
+	ticket := testTicket(uuid.NewString(), "confirmed")
+	ticket.Price = entities.Money{Amount: "10", Currency: "EUR"}
+	ticket.CustomerEmail = "check-in@example.com"
+	ticket.BookingID = bookingID.String()
+
+	sendTicketsStatus(t, entities.TicketsStatusRequest{Tickets: []entities.Ticket{ticket}})
+
+	token := tickettoken.NewSigner("test-secret").Sign(ticket.TicketID)
+
+	assert.Equal(t, http.StatusForbidden, checkIn(t, ticket.TicketID, token+"x"))
+
+	// the ticket is saved asynchronously
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		assert.Equal(t, http.StatusOK, checkIn(t, ticket.TicketID, token))
+	}, 10*time.Second, 100*time.Millisecond)
+
+	assert.Equal(t, http.StatusConflict, checkIn(t, ticket.TicketID, token))
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		resp, err := http.Get(fmt.Sprintf("http://localhost:8000/shows/%s/check-ins", showID))
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer resp.Body.Close()
+
+		var checkIns entities.ShowCheckIns
+		if !assert.NoError(t, json.NewDecoder(resp.Body).Decode(&checkIns)) {
+			return
+		}
+
+		assert.Equal(t, 1, checkIns.CheckedIn)
+	}, 10*time.Second, 100*time.Millisecond)
+}
+
+func checkIn(t assert.TestingT, ticketID, token string) int {
+	payload, err := json.Marshal(entities.CheckInRequest{Token: token})
+	if !assert.NoError(t, err) {
+		return 0
+	}
+
+	resp, err := http.Post(
+		fmt.Sprintf("http://localhost:8000/tickets/%s/check-in", ticketID),
+		"application/json",
+		bytes.NewBuffer(payload),
+	)
+	if !assert.NoError(t, err) {
+		return 0
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode
+}
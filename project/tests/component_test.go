@@ -5,14 +5,22 @@ import (
 	"context"
 	"encoding/json"
 	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
 	"github.com/lithammer/shortuuid/v3"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/redis/go-redis/v9"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"net/http"
 	"os"
+	"sync"
 	"testing"
 	"tickets/internal/app"
+	"tickets/internal/config"
 	"tickets/internal/entities"
+	"tickets/internal/notifications"
 	"tickets/internal/repository"
+	"tickets/internal/tracing"
 	"tickets/tests/mock"
 	"time"
 
@@ -20,36 +28,82 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func TestComponent(t *testing.T) {
-	rdb := redis.NewClient(&redis.Options{
-		Addr: os.Getenv("REDIS_ADDR"),
-	})
-	defer rdb.Close()
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
-	defer cancel()
-	rdb.Ping(ctx)
-
-	db, err := repository.InitDB()
-	if err != nil {
-		panic(err)
-	}
+type testEnv struct {
+	db *sqlx.DB
+
+	receiptClient     *mock.ReceiptMock
+	spreadsheetClient *mock.SpreadsheetsMock
+	filesClient       *mock.FilesMock
+	deadNationClient  *mock.DeadNationClient
+	paymentsService   *mock.PaymentsMock
+	mailer            *notifications.InMemoryMailer
+
+	spans          *tracetest.InMemoryExporter
+	tracerProvider *sdktrace.TracerProvider
+}
+
+var (
+	env          testEnv
+	startAppOnce sync.Once
+)
+
+// startApp starts the app once for all tests in the package.
+func startApp(t *testing.T) testEnv {
+	t.Helper()
 
-	receiptClient := &mock.ReceiptMock{IssuedReceipts: map[string]entities.IssueReceiptRequest{}}
-	spreadsheetClient := &mock.SpreadsheetsMock{Rows: make(map[string][][]string)}
-	filesClient := &mock.FilesMock{Tickets: make(map[string]struct{})}
-	deadNationClient := &mock.DeadNationClient{DeadNationBookings: make([]entities.DeadNationBooking, 0)}
-	paymentsService := &mock.PaymentsMock{}
+	startAppOnce.Do(func() {
+		cfg := config.Default()
+		cfg.HTTP.Addr = ":8000"
+		cfg.Redis.Addr = os.Getenv("REDIS_ADDR")
+		cfg.Postgres.URL = os.Getenv("POSTGRES_URL")
+		cfg.Tickets.TokenSecret = "test-secret"
+		cfg.Refunds.MaxAttempts = 5
+		cfg.Refunds.RetryDelay = 200 * time.Millisecond
+		cfg.Refunds.MaxRetryDelay = time.Second
+
+		rdb := redis.NewClient(&redis.Options{
+			Addr: cfg.Redis.Addr,
+		})
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+		defer cancel()
+		rdb.Ping(ctx)
+
+		db, err := repository.InitDB(cfg.Postgres.URL)
+		if err != nil {
+			panic(err)
+		}
 
-	app1 := app.Initialize(receiptClient, spreadsheetClient, filesClient, deadNationClient, paymentsService, rdb, db)
-	go app1.Start()
+		spans := tracetest.NewInMemoryExporter()
+
+		env = testEnv{
+			db:                db,
+			receiptClient:     &mock.ReceiptMock{IssuedReceipts: map[string]entities.IssueReceiptRequest{}},
+			spreadsheetClient: &mock.SpreadsheetsMock{Rows: make(map[string][][]string)},
+			filesClient:       &mock.FilesMock{Tickets: make(map[string]struct{})},
+			deadNationClient:  &mock.DeadNationClient{DeadNationBookings: make([]entities.DeadNationBooking, 0)},
+			paymentsService:   &mock.PaymentsMock{},
+			mailer:            &notifications.InMemoryMailer{},
+			spans:             spans,
+			tracerProvider:    tracing.InitTracerProvider(spans),
+		}
+
+		app1 := app.Initialize(cfg, env.receiptClient, env.spreadsheetClient, env.filesClient, env.deadNationClient, env.paymentsService, env.mailer, rdb, db, prometheus.NewRegistry())
+		go app1.Start()
+	})
 
 	waitForHttpServer(t)
 
+	return env
+}
+
+func TestComponent(t *testing.T) {
+	env := startApp(t)
+
 	sendTicketsStatus(t, testTicketStatusRequest())
 
-	assertReceiptForTicketIssued(t, receiptClient, testTicket("2", "confirmed"))
-	assertTicketPrinted(t, filesClient, testTicket("2", "confirmed"))
-	assertRowToSheetAdded(t, spreadsheetClient, testTicket("2", "confirmed"), "tickets-to-print")
+	assertReceiptForTicketIssued(t, env.receiptClient, testTicket("2", "confirmed"))
+	assertTicketPrinted(t, env.filesClient, testTicket("2", "confirmed"))
+	assertRowToSheetAdded(t, env.spreadsheetClient, testTicket("2", "confirmed"), "tickets-to-print")
 
 }
 
@@ -100,9 +154,9 @@ func sendTicketsStatus(t *testing.T, req entities.TicketsStatusRequest) {
 	httpReq.Header.Set("Idempotency-Key", uuid.NewString())
 
 	resp, err := http.DefaultClient.Do(httpReq)
+	require.NoError(t, err)
 	defer resp.Body.Close()
 
-	require.NoError(t, err)
 	require.Equal(t, http.StatusOK, resp.StatusCode)
 }
 
@@ -0,0 +1,155 @@
+package tests_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/ThreeDotsLabs/watermill-redisstream/pkg/redisstream"
+	"github.com/ThreeDotsLabs/watermill/components/cqrs"
+	"github.com/ThreeDotsLabs/watermill/message/router/middleware"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"os"
+	"testing"
+	"tickets/internal/broker/event"
+	"tickets/internal/broker/poison"
+	"tickets/internal/entities"
+	"time"
+)
+
+func TestDeadLetters_requeue_keeps_correlation_id(t *testing.T) {
+	env := startApp(t)
+
+	confirmed := entities.TicketBookingConfirmed{
+		Header:        entities.NewEventHeader(uuid.NewString()),
+		TicketID:      uuid.NewString(),
+		CustomerEmail: "dead-letter@example.com",
+		Price:         entities.Money{Amount: "10", Currency: "EUR"},
+	}
+	correlationID := "dead-letter-" + uuid.NewString()
+
+	id := addDeadLetter(t, confirmed, correlationID, event.AllEventsTopic, "EventsStore")
+
+	deadLetter, status := deadLetterByID(t, id)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, event.AllEventsTopic, deadLetter.Topic)
+	assert.Equal(t, "EventsStore", deadLetter.Handler)
+
+	req, err := http.NewRequest(http.MethodPost, "http://localhost:8000/dead-letters/"+id+"/requeue", nil)
+	require.NoError(t, err)
+	req.Header.Set("Correlation-ID", "admin-"+uuid.NewString())
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	_, status = deadLetterByID(t, id)
+	assert.Equal(t, http.StatusNotFound, status, "requeued message should be removed from the dead letters")
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		var storedCorrelationID string
+		err := env.db.GetContext(
+			context.Background(),
+			&storedCorrelationID,
+			"SELECT correlation_id FROM events WHERE event_id = $1",
+			confirmed.Header.ID,
+		)
+		if assert.NoError(t, err) {
+			assert.Equal(t, correlationID, storedCorrelationID)
+		}
+	}, 10*time.Second, 100*time.Millisecond)
+
+	assert.Equal(t, http.StatusNotFound, requeueDeadLetter(t, id), "requeued message can't be requeued again")
+}
+
+func TestDeadLetters_delete(t *testing.T) {
+	startApp(t)
+
+	confirmed := entities.TicketBookingConfirmed{
+		Header:        entities.NewEventHeader(uuid.NewString()),
+		TicketID:      uuid.NewString(),
+		CustomerEmail: "dead-letter@example.com",
+		Price:         entities.Money{Amount: "10", Currency: "EUR"},
+	}
+
+	id := addDeadLetter(t, confirmed, uuid.NewString(), event.AllEventsTopic, "EventsStore")
+
+	assert.Equal(t, http.StatusNoContent, deleteDeadLetter(t, id))
+
+	_, status := deadLetterByID(t, id)
+	assert.Equal(t, http.StatusNotFound, status)
+
+	assert.Equal(t, http.StatusNotFound, deleteDeadLetter(t, id))
+	assert.Equal(t, http.StatusNotFound, requeueDeadLetter(t, id))
+}
+
+// addDeadLetter adds the event to the dead-letter topic, like the poison queue middleware does after retries run out.
+func addDeadLetter(t *testing.T, e any, correlationID, topic, handler string) string {
+	t.Helper()
+
+	msg, err := cqrs.JSONMarshaler{GenerateName: cqrs.StructName}.Marshal(e)
+	require.NoError(t, err)
+
+	msg.Metadata.Set("correlation_id", correlationID)
+	msg.Metadata.Set(middleware.PoisonedTopicKey, topic)
+	msg.Metadata.Set(middleware.PoisonedHandlerKey, handler)
+	msg.Metadata.Set(middleware.PoisonedSubscriberKey, "test")
+	msg.Metadata.Set(middleware.ReasonForPoisonedKey, "test failure")
+
+	values, err := redisstream.DefaultMarshallerUnmarshaller{}.Marshal(poison.Topic, msg)
+	require.NoError(t, err)
+
+	rdb := redis.NewClient(&redis.Options{
+		Addr: os.Getenv("REDIS_ADDR"),
+	})
+	defer rdb.Close()
+
+	id, err := rdb.XAdd(context.Background(), &redis.XAddArgs{
+		Stream: poison.Topic,
+		Values: values,
+	}).Result()
+	require.NoError(t, err)
+
+	return id
+}
+
+func deadLetterByID(t *testing.T, id string) (entities.DeadLetter, int) {
+	t.Helper()
+
+	resp, err := http.Get("http://localhost:8000/dead-letters/" + id)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var deadLetter entities.DeadLetter
+	if resp.StatusCode == http.StatusOK {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&deadLetter))
+	}
+
+	return deadLetter, resp.StatusCode
+}
+
+func requeueDeadLetter(t *testing.T, id string) int {
+	t.Helper()
+
+	resp, err := http.Post("http://localhost:8000/dead-letters/"+id+"/requeue", "application/json", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	return resp.StatusCode
+}
+
+func deleteDeadLetter(t *testing.T, id string) int {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodDelete, "http://localhost:8000/dead-letters/"+id, nil)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	return resp.StatusCode
+}
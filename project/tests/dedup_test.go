@@ -0,0 +1,64 @@
+package tests_test
+
+import (
+	"context"
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/components/cqrs"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+	"tickets/internal/broker"
+	"tickets/internal/broker/event"
+	"tickets/internal/entities"
+	"time"
+)
+
+func TestDeduplicator_skips_redelivered_event(t *testing.T) {
+	env := startApp(t)
+
+	eventBus := newTestEventBus(t)
+
+	confirmed := entities.TicketBookingConfirmed{
+		Header:        entities.NewEventHeader(uuid.NewString()),
+		TicketID:      uuid.NewString(),
+		CustomerEmail: "dedup@example.com",
+		Price:         entities.Money{Amount: "10", Currency: "EUR"},
+	}
+
+	// the same event delivered twice
+	for i := 0; i < 2; i++ {
+		require.NoError(t, eventBus.Publish(context.Background(), confirmed))
+	}
+
+	// published after the duplicate, so once it's handled, the duplicate was handled too
+	marker := confirmed
+	marker.Header = entities.NewEventHeader(uuid.NewString())
+	marker.TicketID = uuid.NewString()
+	require.NoError(t, eventBus.Publish(context.Background(), marker))
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		assert.Contains(t, rowsPerTicket(env.spreadsheetClient, "tickets-to-print"), marker.TicketID)
+	}, 10*time.Second, 100*time.Millisecond)
+
+	assert.Equal(t, 1, rowsPerTicket(env.spreadsheetClient, "tickets-to-print")[confirmed.TicketID])
+}
+
+// newTestEventBus publishes events straight to Redis, like redelivered messages arrive.
+func newTestEventBus(t *testing.T) *cqrs.EventBus {
+	t.Helper()
+
+	rdb := redis.NewClient(&redis.Options{
+		Addr: os.Getenv("REDIS_ADDR"),
+	})
+	t.Cleanup(func() {
+		_ = rdb.Close()
+	})
+
+	eventBus, err := event.NewEventBus(broker.NewRedisPublisher(rdb, watermill.NopLogger{}))
+	require.NoError(t, err)
+
+	return eventBus
+}
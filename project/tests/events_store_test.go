@@ -0,0 +1,42 @@
+package tests_test
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"tickets/internal/entities"
+	"time"
+)
+
+func TestEventsStore_stores_derived_events(t *testing.T) {
+	env := startApp(t)
+
+	ticket := testTicket(uuid.NewString(), "confirmed")
+
+	sendTicketsStatus(t, entities.TicketsStatusRequest{Tickets: []entities.Ticket{ticket}})
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		var stored []struct {
+			EventID   string `db:"event_id"`
+			EventName string `db:"event_name"`
+		}
+		err := env.db.SelectContext(context.Background(), &stored, `
+			SELECT event_id, event_name FROM events
+			WHERE payload->>'ticket_id' = $1 AND event_name IN ('TicketBookingConfirmed', 'TicketReceiptIssued')`,
+			ticket.TicketID,
+		)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		eventIDs := make(map[string]string, len(stored))
+		for _, event := range stored {
+			eventIDs[event.EventName] = event.EventID
+		}
+
+		assert.Contains(t, eventIDs, "TicketBookingConfirmed")
+		assert.Contains(t, eventIDs, "TicketReceiptIssued")
+		assert.NotEqual(t, eventIDs["TicketBookingConfirmed"], eventIDs["TicketReceiptIssued"])
+	}, 10*time.Second, 100*time.Millisecond)
+}
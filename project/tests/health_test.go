@@ -0,0 +1,44 @@
+package tests_test
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"tickets/internal/entities"
+	"time"
+)
+
+func TestHealth_ready_reports_every_dependency(t *testing.T) {
+	startApp(t)
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		resp, err := http.Get("http://localhost:8000/health/ready")
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer resp.Body.Close()
+
+		var report entities.HealthReport
+		if !assert.NoError(t, json.NewDecoder(resp.Body).Decode(&report)) {
+			return
+		}
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "report: %+v", report)
+		assert.Equal(t, entities.HealthStatusOK, report.Status)
+		for _, name := range []string{"postgres", "redis", "router", "outbox"} {
+			assert.Equal(t, entities.HealthStatusOK, report.Checks[name].Status, "check %s: %+v", name, report.Checks[name])
+		}
+	}, 10*time.Second, 100*time.Millisecond)
+}
+
+func TestHealth_live(t *testing.T) {
+	startApp(t)
+
+	resp, err := http.Get("http://localhost:8000/health/live")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
@@ -0,0 +1,70 @@
+package tests_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"testing"
+	"tickets/internal/entities"
+	"time"
+)
+
+func TestIdempotencyKey_book_tickets(t *testing.T) {
+	env := startApp(t)
+
+	showID := createShow(t, entities.Show{
+		DeadNationID:   uuid.New(),
+		NumberOfTicket: 10,
+		StartTime:      time.Now().Add(24 * time.Hour),
+		Title:          "Idempotent show",
+		Venue:          "Test venue",
+	})
+
+	booking := entities.Booking{
+		ShowID:          showID,
+		NumberOfTickets: 2,
+		CustomerEmail:   uuid.NewString() + "@example.com",
+	}
+	key := uuid.NewString()
+
+	status, first := postWithIdempotencyKey(t, "/book-tickets", key, booking)
+	require.Equal(t, http.StatusCreated, status)
+
+	status, repeated := postWithIdempotencyKey(t, "/book-tickets", key, booking)
+	assert.Equal(t, http.StatusCreated, status)
+	assert.JSONEq(t, string(first), string(repeated), "repeated request should get the original response")
+
+	var bookings int
+	err := env.db.Get(&bookings, "SELECT COUNT(*) FROM bookings WHERE customer_email = $1", booking.CustomerEmail)
+	require.NoError(t, err)
+	assert.Equal(t, 1, bookings)
+
+	booking.NumberOfTickets = 3
+	status, _ = postWithIdempotencyKey(t, "/book-tickets", key, booking)
+	assert.Equal(t, http.StatusConflict, status, "key reused with a different body")
+}
+
+func postWithIdempotencyKey(t *testing.T, path, key string, body any) (int, []byte) {
+	t.Helper()
+
+	payload, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, "http://localhost:8000"+path, bytes.NewBuffer(payload))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", key)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return resp.StatusCode, respBody
+}
@@ -0,0 +1,36 @@
+package tests_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestMetrics_exposes_broker_and_http_metrics(t *testing.T) {
+	env := startApp(t)
+
+	sendTicketsStatus(t, testTicketStatusRequest())
+	assertReceiptForTicketIssued(t, env.receiptClient, testTicket("2", "confirmed"))
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		resp, err := http.Get("http://localhost:8000/metrics")
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Contains(t, string(body), `tickets_messages_acked_total{handler="IssueReceipt"}`)
+		assert.Contains(t, string(body), `tickets_handler_duration_seconds_count{handler="IssueReceipt"}`)
+		assert.Contains(t, string(body), `tickets_http_request_duration_seconds_count{method="POST",route="/tickets-status",status="200"}`)
+		assert.Contains(t, string(body), `tickets_outbox_lag_seconds`)
+	}, 10*time.Second, 100*time.Millisecond)
+}
@@ -10,17 +10,33 @@ import (
 type FilesMock struct {
 	mock    sync.Mutex
 	Tickets map[string]struct{}
+	Files   map[string]entities.TicketFile
 }
 
-func (f *FilesMock) StoreTicketContent(ctx context.Context, ticket entities.TicketBookingConfirmed) error {
+func (f *FilesMock) StoreTicketContent(ctx context.Context, file entities.TicketFile) error {
+	f.mock.Lock()
+	defer f.mock.Unlock()
 
-	if _, ok := f.Tickets[ticket.TicketID]; !ok {
-		f.Tickets[ticket.TicketID] = struct{}{}
+	if _, ok := f.Tickets[file.TicketID]; !ok {
+		f.Tickets[file.TicketID] = struct{}{}
 	}
 
+	if f.Files == nil {
+		f.Files = make(map[string]entities.TicketFile)
+	}
+	f.Files[file.Name] = file
+
 	return nil
 }
 
+func (f *FilesMock) File(name string) (entities.TicketFile, bool) {
+	f.mock.Lock()
+	defer f.mock.Unlock()
+
+	file, ok := f.Files[name]
+	return file, ok
+}
+
 func (f *FilesMock) DownloadTicketContent(ctx context.Context, ticketID string) (struct{}, error) {
 	f.mock.Lock()
 	defer f.mock.Unlock()
@@ -2,6 +2,7 @@ package mock
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"tickets/internal/entities"
 )
@@ -9,13 +10,49 @@ import (
 type PaymentsMock struct {
 	lock    sync.Mutex
 	Refunds []entities.PaymentRefund
+
+	failingRefunds map[string]struct{}
 }
 
 func (c *PaymentsMock) PutRefundsWithResponse(ctx context.Context, refundPayment entities.PaymentRefund) error {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
+	if _, ok := c.failingRefunds[refundPayment.TicketID]; ok {
+		return fmt.Errorf("refund of ticket %s failed", refundPayment.TicketID)
+	}
+
 	c.Refunds = append(c.Refunds, refundPayment)
 
 	return nil
 }
+
+// FailRefunds makes refunds of the ticket fail until it's called with false.
+func (c *PaymentsMock) FailRefunds(ticketID string, fail bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.failingRefunds == nil {
+		c.failingRefunds = make(map[string]struct{})
+	}
+
+	if fail {
+		c.failingRefunds[ticketID] = struct{}{}
+	} else {
+		delete(c.failingRefunds, ticketID)
+	}
+}
+
+// RefundsOf returns the successful refunds of the ticket.
+func (c *PaymentsMock) RefundsOf(ticketID string) []entities.PaymentRefund {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var refunds []entities.PaymentRefund
+	for _, r := range c.Refunds {
+		if r.TicketID == ticketID {
+			refunds = append(refunds, r)
+		}
+	}
+	return refunds
+}
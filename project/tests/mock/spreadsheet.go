@@ -19,3 +19,11 @@ func (s *SpreadsheetsMock) AppendRow(ctx context.Context, spreadsheetName string
 
 	return nil
 }
+
+// SheetRows returns a copy of the rows appended to the sheet, safe to use while handlers append more.
+func (s *SpreadsheetsMock) SheetRows(spreadsheetName string) [][]string {
+	s.mock.Lock()
+	defer s.mock.Unlock()
+
+	return append([][]string(nil), s.Rows[spreadsheetName]...)
+}
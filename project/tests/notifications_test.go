@@ -0,0 +1,132 @@
+package tests_test
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"tickets/internal/entities"
+	"tickets/internal/notifications"
+	"time"
+)
+
+func TestNotifications_confirmed_ticket(t *testing.T) {
+	env := startApp(t)
+
+	ticket := testTicket(uuid.NewString(), "confirmed")
+	ticket.CustomerEmail = ticket.TicketID + "@example.com"
+
+	sendTicketsStatus(t, entities.TicketsStatusRequest{Tickets: []entities.Ticket{ticket}})
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		emails := env.mailer.SentTo(ticket.CustomerEmail)
+
+		confirmed := emailsWithSubject(emails, "Your ticket is confirmed")
+		if assert.Len(t, confirmed, 1) {
+			assert.Contains(t, confirmed[0].Body, ticket.TicketID)
+		}
+		assert.Len(t, emailsWithSubject(emails, "Receipt "), 1)
+	}, 10*time.Second, 100*time.Millisecond)
+}
+
+func TestNotifications_booking_made(t *testing.T) {
+	env := startApp(t)
+
+	showID := createShow(t, entities.Show{
+		DeadNationID:   uuid.New(),
+		NumberOfTicket: 10,
+		StartTime:      time.Now().Add(24 * time.Hour),
+		Title:          "Notified show",
+		Venue:          "Test venue",
+	})
+
+	email := uuid.NewString() + "@example.com"
+	bookingID := bookTicketsForID(t, entities.Booking{
+		ShowID:          showID,
+		NumberOfTickets: 2,
+		CustomerEmail:   email,
+	})
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		assert.Len(t, emailsWithSubject(env.mailer.SentTo(email), "Your booking "+bookingID), 1)
+	}, 10*time.Second, 100*time.Millisecond)
+}
+
+func TestNotifications_refunded_ticket(t *testing.T) {
+	env := startApp(t)
+
+	ticket := testTicket(uuid.NewString(), "confirmed")
+	ticket.CustomerEmail = ticket.TicketID + "@example.com"
+
+	sendTicketsStatus(t, entities.TicketsStatusRequest{Tickets: []entities.Ticket{ticket}})
+	refundTicket(t, ticket.TicketID)
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		refunded := emailsWithSubject(env.mailer.SentTo(ticket.CustomerEmail), "Your ticket was refunded")
+		if assert.Len(t, refunded, 1) {
+			assert.Contains(t, refunded[0].Body, ticket.TicketID)
+		}
+	}, 10*time.Second, 100*time.Millisecond)
+}
+
+func TestNotifications_redelivered_event_sends_one_email(t *testing.T) {
+	env := startApp(t)
+
+	eventBus := newTestEventBus(t)
+
+	confirmed := entities.TicketBookingConfirmed{
+		Header:        entities.NewEventHeader(uuid.NewString()),
+		TicketID:      uuid.NewString(),
+		CustomerEmail: uuid.NewString() + "@example.com",
+		Price:         entities.Money{Amount: "10", Currency: "EUR"},
+	}
+
+	// the same event delivered twice
+	for i := 0; i < 2; i++ {
+		require.NoError(t, eventBus.Publish(context.Background(), confirmed))
+	}
+
+	// published after the duplicate, so once its email is sent, the duplicate was handled too
+	marker := confirmed
+	marker.Header = entities.NewEventHeader(uuid.NewString())
+	marker.TicketID = uuid.NewString()
+	marker.CustomerEmail = uuid.NewString() + "@example.com"
+	require.NoError(t, eventBus.Publish(context.Background(), marker))
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		assert.NotEmpty(t, emailsWithSubject(env.mailer.SentTo(marker.CustomerEmail), "Your ticket is confirmed"))
+	}, 10*time.Second, 100*time.Millisecond)
+
+	assert.Len(t, emailsWithSubject(env.mailer.SentTo(confirmed.CustomerEmail), "Your ticket is confirmed"), 1)
+}
+
+func emailsWithSubject(emails []notifications.Email, subjectPrefix string) []notifications.Email {
+	var matching []notifications.Email
+	for _, email := range emails {
+		if strings.HasPrefix(email.Subject, subjectPrefix) {
+			matching = append(matching, email)
+		}
+	}
+	return matching
+}
+
+func TestNotifications_ticket_without_email_is_skipped(t *testing.T) {
+	env := startApp(t)
+
+	ticket := testTicket(uuid.NewString(), "confirmed")
+	ticket.CustomerEmail = ""
+
+	marker := testTicket(uuid.NewString(), "confirmed")
+	marker.CustomerEmail = marker.TicketID + "@example.com"
+
+	sendTicketsStatus(t, entities.TicketsStatusRequest{Tickets: []entities.Ticket{ticket, marker}})
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		assert.NotEmpty(t, emailsWithSubject(env.mailer.SentTo(marker.CustomerEmail), "Your ticket is confirmed"))
+		assert.Contains(t, rowsPerTicket(env.spreadsheetClient, "tickets-to-print"), ticket.TicketID)
+	}, 10*time.Second, 100*time.Millisecond)
+
+	assert.Empty(t, env.mailer.SentTo(""))
+}
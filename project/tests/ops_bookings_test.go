@@ -0,0 +1,136 @@
+package tests_test
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"tickets/internal/entities"
+	"time"
+)
+
+func TestOpsBookings_follow_booking_lifecycle(t *testing.T) {
+	startApp(t)
+
+	showID := createShow(t, entities.Show{
+		DeadNationID:   uuid.New(),
+		NumberOfTicket: 10,
+		StartTime:      time.Now().Add(24 * time.Hour),
+		Title:          "Ops show",
+		Venue:          "Test venue",
+	})
+
+	bookingID := bookTicketsForID(t, entities.Booking{
+		ShowID:          showID,
+		NumberOfTickets: 1,
+		CustomerEmail:   "ops@example.com",
+	})
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		booking, status := opsBooking(t, bookingID)
+		if assert.Equal(t, http.StatusOK, status) {
+			assert.Equal(t, bookingID, booking.BookingID.String())
+			assert.False(t, booking.BookedAt.IsZero())
+			assert.Empty(t, booking.Tickets)
+		}
+	}, 10*time.Second, 100*time.Millisecond)
+
+	ticket := testTicket(uuid.NewString(), "confirmed")
+	ticket.CustomerEmail = "ops@example.com"
+	ticket.BookingID = bookingID
+
+	sendTicketsStatus(t, entities.TicketsStatusRequest{Tickets: []entities.Ticket{ticket}})
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		booking, status := opsBooking(t, bookingID)
+		if !assert.Equal(t, http.StatusOK, status) {
+			return
+		}
+
+		opsTicket, ok := booking.Tickets[ticket.TicketID]
+		if !assert.True(t, ok, "ticket %s not in booking", ticket.TicketID) {
+			return
+		}
+
+		assert.Equal(t, "confirmed", opsTicket.Status)
+		assert.Equal(t, ticket.Price.Amount, opsTicket.PriceAmount)
+		assert.Equal(t, ticket.Price.Currency, opsTicket.PriceCurrency)
+		assert.Equal(t, ticket.CustomerEmail, opsTicket.CustomerEmail)
+		assert.NotEmpty(t, opsTicket.ReceiptNumber)
+		assert.False(t, opsTicket.ReceiptIssuedAt.IsZero())
+		assert.NotEmpty(t, opsTicket.PrintedFileName)
+		assert.False(t, opsTicket.PrintedAt.IsZero())
+	}, 10*time.Second, 100*time.Millisecond)
+
+	var listed bool
+	for cursor := ""; ; {
+		page := opsBookingsPage(t, "limit=10&cursor="+cursor)
+		assert.LessOrEqual(t, len(page.Items), 10)
+
+		for _, booking := range page.Items {
+			if booking.BookingID.String() == bookingID {
+				listed = true
+				assert.Contains(t, booking.Tickets, ticket.TicketID)
+			}
+		}
+
+		if listed || page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	assert.True(t, listed, "booking %s not listed", bookingID)
+}
+
+func TestOpsBookings_invalid_page(t *testing.T) {
+	startApp(t)
+
+	for _, query := range []string{"limit=0", "limit=1000", "cursor=not-a-cursor"} {
+		resp, err := http.Get("http://localhost:8000/ops/bookings?" + query)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+	}
+}
+
+func TestOpsBookings_not_found(t *testing.T) {
+	startApp(t)
+
+	_, status := opsBooking(t, uuid.NewString())
+	assert.Equal(t, http.StatusNotFound, status)
+
+	_, status = opsBooking(t, "not-a-uuid")
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+func opsBooking(t assert.TestingT, bookingID string) (entities.OpsBooking, int) {
+	resp, err := http.Get("http://localhost:8000/ops/bookings/" + bookingID)
+	if !assert.NoError(t, err) {
+		return entities.OpsBooking{}, 0
+	}
+	defer resp.Body.Close()
+
+	var booking entities.OpsBooking
+	if resp.StatusCode == http.StatusOK {
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&booking))
+	}
+
+	return booking, resp.StatusCode
+}
+
+func opsBookingsPage(t *testing.T, query string) entities.OpsBookingPage {
+	t.Helper()
+
+	resp, err := http.Get("http://localhost:8000/ops/bookings?" + query)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var page entities.OpsBookingPage
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+
+	return page
+}
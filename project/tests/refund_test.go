@@ -0,0 +1,163 @@
+package tests_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"tickets/internal/entities"
+	"time"
+)
+
+func TestRefund_failed_halfway_is_retried_manually(t *testing.T) {
+	env := startApp(t)
+
+	ticket := testTicket(uuid.NewString(), "confirmed")
+	sendTicketsStatus(t, entities.TicketsStatusRequest{Tickets: []entities.Ticket{ticket}})
+
+	env.paymentsService.FailRefunds(ticket.TicketID, true)
+	refundTicket(t, ticket.TicketID)
+
+	// the receipt is voided, but the payment refund fails until the refund gives up
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		r, ok := refundOf(t, "/refunds?failed=true", ticket.TicketID)
+		if assert.True(t, ok, "refund should be failed") {
+			assert.Equal(t, entities.RefundStatusReceiptVoided, r.Status)
+		}
+	}, 30*time.Second, 100*time.Millisecond)
+
+	assert.Equal(t, http.StatusNotFound, retryRefund(t, uuid.NewString()), "unknown refund")
+	assert.Empty(t, env.paymentsService.RefundsOf(ticket.TicketID))
+
+	env.paymentsService.FailRefunds(ticket.TicketID, false)
+	require.Equal(t, http.StatusAccepted, retryRefund(t, ticket.TicketID))
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		_, ok := refundOf(t, "/refunds?status="+entities.RefundStatusCompleted, ticket.TicketID)
+		assert.True(t, ok, "refund should be completed")
+	}, 10*time.Second, 100*time.Millisecond)
+
+	assert.Len(t, env.paymentsService.RefundsOf(ticket.TicketID), 1)
+	assert.Equal(t, http.StatusConflict, retryRefund(t, ticket.TicketID), "completed refund can't be retried")
+}
+
+func TestRefund_failed_step_is_retried_after_delay(t *testing.T) {
+	env := startApp(t)
+
+	ticket := testTicket(uuid.NewString(), "confirmed")
+	sendTicketsStatus(t, entities.TicketsStatusRequest{Tickets: []entities.Ticket{ticket}})
+
+	env.paymentsService.FailRefunds(ticket.TicketID, true)
+	refundTicket(t, ticket.TicketID)
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		r, ok := refundOf(t, "/refunds", ticket.TicketID)
+		if assert.True(t, ok) {
+			assert.NotZero(t, r.Attempts)
+			assert.NotNil(t, r.NextAttemptAt)
+		}
+	}, 10*time.Second, 10*time.Millisecond)
+
+	// the payment provider is back before the attempts run out
+	env.paymentsService.FailRefunds(ticket.TicketID, false)
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		r, ok := refundOf(t, "/refunds?status="+entities.RefundStatusCompleted, ticket.TicketID)
+		if assert.True(t, ok, "refund should be completed without manual retry") {
+			assert.Nil(t, r.FailedAt)
+		}
+	}, 10*time.Second, 100*time.Millisecond)
+
+	assert.Len(t, env.paymentsService.RefundsOf(ticket.TicketID), 1)
+}
+
+func TestRefund_shows_up_everywhere(t *testing.T) {
+	env := startApp(t)
+
+	showID := createShow(t, entities.Show{
+		DeadNationID:   uuid.New(),
+		NumberOfTicket: 10,
+		StartTime:      time.Now().Add(24 * time.Hour),
+		Title:          "Refunded show",
+		Venue:          "Test venue",
+	})
+	bookingID := bookTicketsForID(t, entities.Booking{
+		ShowID:          showID,
+		NumberOfTickets: 1,
+		CustomerEmail:   "refund@example.com",
+	})
+
+	ticket := testTicket(uuid.NewString(), "confirmed")
+	ticket.BookingID = bookingID
+	sendTicketsStatus(t, entities.TicketsStatusRequest{Tickets: []entities.Ticket{ticket}})
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		booking, status := opsBooking(t, bookingID)
+		if assert.Equal(t, http.StatusOK, status) {
+			assert.Contains(t, booking.Tickets, ticket.TicketID)
+		}
+	}, 10*time.Second, 100*time.Millisecond)
+
+	refundTicket(t, ticket.TicketID)
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		booking, status := opsBooking(t, bookingID)
+		if assert.Equal(t, http.StatusOK, status) {
+			assert.Equal(t, "refunded", booking.Tickets[ticket.TicketID].Status)
+		}
+
+		assert.Equal(t, 1, rowsPerTicket(env.spreadsheetClient, "tickets-refunded")[ticket.TicketID])
+
+		var refundedAt *time.Time
+		err := env.db.GetContext(context.Background(), &refundedAt, "SELECT refunded_at FROM tickets WHERE ticket_id = $1", ticket.TicketID)
+		if assert.NoError(t, err) {
+			assert.NotNil(t, refundedAt)
+		}
+	}, 10*time.Second, 100*time.Millisecond)
+}
+
+func refundTicket(t *testing.T, ticketID string) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPut, "http://localhost:8000/ticket-refund/"+ticketID, nil)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+}
+
+func retryRefund(t *testing.T, ticketID string) int {
+	t.Helper()
+
+	resp, err := http.Post("http://localhost:8000/refunds/"+ticketID+"/retry", "application/json", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	return resp.StatusCode
+}
+
+func refundOf(t assert.TestingT, path, ticketID string) (entities.Refund, bool) {
+	resp, err := http.Get("http://localhost:8000" + path)
+	if !assert.NoError(t, err) {
+		return entities.Refund{}, false
+	}
+	defer resp.Body.Close()
+
+	var refunds []entities.Refund
+	if !assert.NoError(t, json.NewDecoder(resp.Body).Decode(&refunds)) {
+		return entities.Refund{}, false
+	}
+
+	for _, r := range refunds {
+		if r.TicketID == ticketID {
+			return r, true
+		}
+	}
+	return entities.Refund{}, false
+}
@@ -0,0 +1,135 @@
+package tests_test
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"os"
+	"testing"
+	"tickets/internal/app"
+	"tickets/internal/config"
+	"tickets/internal/entities"
+	"tickets/internal/notifications"
+	"tickets/internal/repository"
+	"tickets/tests/mock"
+	"time"
+)
+
+func TestShutdown_restart_does_not_lose_or_duplicate_messages(t *testing.T) {
+	// tickets are sent through the app started by startApp, so they are published while the tested app is down
+	startApp(t)
+
+	cfg := config.Default()
+	cfg.HTTP.Addr = ":8001"
+	cfg.Redis.Addr = os.Getenv("REDIS_ADDR")
+	cfg.Postgres.URL = os.Getenv("POSTGRES_URL")
+	cfg.Tickets.TokenSecret = "test-secret"
+	// separate consumer groups, so the app started by startApp doesn't take these messages
+	cfg.Broker.ConsumerGroupPrefix = "svc-tickets-restart-test."
+	cfg.Shutdown.DrainTimeout = 10 * time.Second
+
+	spreadsheets := &mock.SpreadsheetsMock{Rows: make(map[string][][]string)}
+
+	var ticketIDs []string
+	sendTickets := func(count int) {
+		req := entities.TicketsStatusRequest{}
+		for i := 0; i < count; i++ {
+			ticketID := uuid.NewString()
+			ticketIDs = append(ticketIDs, ticketID)
+			req.Tickets = append(req.Tickets, testTicket(ticketID, "confirmed"))
+		}
+		sendTicketsStatus(t, req)
+	}
+
+	stop := runApp(t, cfg, spreadsheets)
+
+	sendTickets(20)
+	// shut down while the tickets are still being handled
+	stop()
+
+	sendTickets(20)
+
+	stop = runApp(t, cfg, spreadsheets)
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		rows := rowsPerTicket(spreadsheets, cfg.Spreadsheets.TicketsToPrint)
+		for _, ticketID := range ticketIDs {
+			assert.Contains(t, rows, ticketID)
+		}
+	}, 30*time.Second, 100*time.Millisecond)
+
+	stop()
+
+	rows := rowsPerTicket(spreadsheets, cfg.Spreadsheets.TicketsToPrint)
+	for _, ticketID := range ticketIDs {
+		assert.Equal(t, 1, rows[ticketID], "ticket %s should be added to the sheet once", ticketID)
+	}
+}
+
+// runApp starts a separate app instance and returns a function that shuts it down gracefully.
+func runApp(t *testing.T, cfg config.Config, spreadsheets *mock.SpreadsheetsMock) func() {
+	t.Helper()
+
+	db, err := repository.InitDB(cfg.Postgres.URL)
+	require.NoError(t, err)
+
+	rdb := redis.NewClient(&redis.Options{
+		Addr: cfg.Redis.Addr,
+	})
+
+	a := app.Initialize(
+		cfg,
+		&mock.ReceiptMock{IssuedReceipts: map[string]entities.IssueReceiptRequest{}},
+		spreadsheets,
+		&mock.FilesMock{Tickets: make(map[string]struct{})},
+		&mock.DeadNationClient{DeadNationBookings: make([]entities.DeadNationBooking, 0)},
+		&mock.PaymentsMock{},
+		&notifications.InMemoryMailer{},
+		rdb,
+		db,
+		prometheus.NewRegistry(),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- a.Run(ctx)
+	}()
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		resp, err := http.Get("http://localhost" + cfg.HTTP.Addr + "/health/live")
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}, 10*time.Second, 50*time.Millisecond)
+
+	return func() {
+		t.Helper()
+
+		cancel()
+
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(cfg.Shutdown.DrainTimeout + 5*time.Second):
+			t.Fatal("app didn't shut down in time")
+		}
+	}
+}
+
+func rowsPerTicket(spreadsheets *mock.SpreadsheetsMock, sheetName string) map[string]int {
+	rows := spreadsheets.SheetRows(sheetName)
+
+	count := make(map[string]int, len(rows))
+	for _, row := range rows {
+		count[row[0]]++
+	}
+	return count
+}
@@ -0,0 +1,33 @@
+package tests_test
+
+import (
+	"context"
+	"database/sql"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"tickets/internal/entities"
+	"time"
+)
+
+func TestTicketPrinted_after_file_is_stored(t *testing.T) {
+	env := startApp(t)
+
+	ticket := testTicket(uuid.NewString(), "confirmed")
+	ticket.Price = entities.Money{Amount: "10", Currency: "EUR"}
+	ticket.CustomerEmail = "printed@example.com"
+
+	sendTicketsStatus(t, entities.TicketsStatusRequest{Tickets: []entities.Ticket{ticket}})
+
+	assertTicketPrinted(t, env.filesClient, ticket)
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		var printedAt sql.NullTime
+		err := env.db.GetContext(context.Background(), &printedAt, `SELECT printed_at FROM tickets WHERE ticket_id = $1`, ticket.TicketID)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.True(t, printedAt.Valid, "ticket should be marked as printed")
+	}, 10*time.Second, 100*time.Millisecond)
+}
@@ -0,0 +1,55 @@
+package tests_test
+
+import (
+	"bytes"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"tickets/internal/entities"
+	"time"
+)
+
+func TestTicketRender_includes_show_details(t *testing.T) {
+	env := startApp(t)
+
+	showID := createShow(t, entities.Show{
+		DeadNationID:   uuid.New(),
+		NumberOfTicket: 10,
+		StartTime:      time.Now().Add(24 * time.Hour),
+		Title:          "Rendered show",
+		Venue:          "Rendered venue",
+	})
+
+	bookingID := uuid.New()
+	_, err := env.db.Exec(
+		`INSERT INTO bookings (booking_id, show_id, number_of_tickets, customer_email) VALUES ($1, $2, 1, $3)`,
+		bookingID, showID, "render@example.com",
+	)
+	require.NoError(t, err)
+
+	ticket := testTicket(uuid.NewString(), "confirmed")
+	ticket.CustomerEmail = "render@example.com"
+	ticket.BookingID = bookingID.String()
+
+	sendTicketsStatus(t, entities.TicketsStatusRequest{Tickets: []entities.Ticket{ticket}})
+
+	var html, pdf entities.TicketFile
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		var ok bool
+		html, ok = env.filesClient.File(ticket.TicketID + "-ticket.html")
+		assert.True(t, ok, "html ticket not stored")
+
+		pdf, ok = env.filesClient.File(ticket.TicketID + "-ticket.pdf")
+		assert.True(t, ok, "pdf ticket not stored")
+	}, 10*time.Second, 100*time.Millisecond)
+
+	assert.Equal(t, "text/html", html.ContentType)
+	assert.Contains(t, string(html.Content), "Rendered show")
+	assert.Contains(t, string(html.Content), "Rendered venue")
+	assert.Contains(t, string(html.Content), "render@example.com")
+	assert.Contains(t, string(html.Content), "data:image/png;base64,")
+
+	assert.Equal(t, "application/pdf", pdf.ContentType)
+	assert.True(t, bytes.HasPrefix(pdf.Content, []byte("%PDF")))
+}
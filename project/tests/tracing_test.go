@@ -0,0 +1,64 @@
+package tests_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"testing"
+	"tickets/internal/entities"
+	"time"
+)
+
+func TestTracing_booking_is_traced_to_dead_nation(t *testing.T) {
+	env := startApp(t)
+
+	showID := createShow(t, entities.Show{
+		DeadNationID:   uuid.New(),
+		NumberOfTicket: 10,
+		StartTime:      time.Now().Add(24 * time.Hour),
+		Title:          "Traced show",
+		Venue:          "Test venue",
+	})
+
+	traceID := trace.TraceID(uuid.New())
+	parentSpanID := trace.SpanID{1, 2, 3, 4, 5, 6, 7, 8}
+
+	payload, err := json.Marshal(entities.Booking{
+		ShowID:          showID,
+		NumberOfTickets: 1,
+		CustomerEmail:   "traced@example.com",
+	})
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, "http://localhost:8000/book-tickets", bytes.NewBuffer(payload))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("traceparent", fmt.Sprintf("00-%s-%s-01", traceID, parentSpanID))
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		require.NoError(t, env.tracerProvider.ForceFlush(context.Background()))
+
+		names := map[string]bool{}
+		for _, span := range env.spans.GetSpans() {
+			if span.SpanContext.TraceID() == traceID {
+				names[span.Name] = true
+			}
+		}
+
+		assert.True(t, names["POST /book-tickets"], "spans: %v", names)
+		assert.True(t, names["sql transaction"], "spans: %v", names)
+		assert.True(t, names["handle events_forwarder"], "spans: %v", names)
+		assert.True(t, names["handle BookPlaceInDeadNation"], "spans: %v", names)
+	}, 10*time.Second, 100*time.Millisecond)
+}
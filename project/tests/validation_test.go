@@ -0,0 +1,146 @@
+package tests_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"tickets/internal/entities"
+	"time"
+)
+
+func TestValidation(t *testing.T) {
+	startApp(t)
+
+	showID := createShow(t, entities.Show{
+		DeadNationID:   uuid.New(),
+		NumberOfTicket: 10,
+		StartTime:      time.Now().Add(24 * time.Hour),
+		Title:          "Validation show",
+		Venue:          "Test venue",
+	})
+
+	testCases := []struct {
+		name string
+		path string
+		body string
+
+		expectedStatus int
+		expectedFields []string
+	}{
+		{
+			name:           "malformed body",
+			path:           "/book-tickets",
+			body:           `{"show_id": `,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "wrong field type",
+			path:           "/book-tickets",
+			body:           fmt.Sprintf(`{"show_id": %q, "number_of_tickets": "two", "customer_email": "a@example.com"}`, showID),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "negative number of tickets",
+			path:           "/book-tickets",
+			body:           fmt.Sprintf(`{"show_id": %q, "number_of_tickets": -1, "customer_email": "a@example.com"}`, showID),
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedFields: []string{"number_of_tickets"},
+		},
+		{
+			name:           "bad email",
+			path:           "/book-tickets",
+			body:           fmt.Sprintf(`{"show_id": %q, "number_of_tickets": 1, "customer_email": "not-an-email"}`, showID),
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedFields: []string{"customer_email"},
+		},
+		{
+			name:           "all booking fields missing",
+			path:           "/book-tickets",
+			body:           `{}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedFields: []string{"show_id", "number_of_tickets", "customer_email"},
+		},
+		{
+			name:           "unknown show",
+			path:           "/book-tickets",
+			body:           fmt.Sprintf(`{"show_id": %q, "number_of_tickets": 1, "customer_email": "a@example.com"}`, uuid.New()),
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedFields: []string{"show_id"},
+		},
+		{
+			name: "show in the past",
+			path: "/shows",
+			body: fmt.Sprintf(
+				`{"dead_nation_id": %q, "number_of_tickets": 10, "start_time": %q, "title": "Past show", "venue": "Test venue"}`,
+				uuid.New(), time.Now().Add(-time.Hour).Format(time.RFC3339),
+			),
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedFields: []string{"start_time"},
+		},
+		{
+			name:           "unknown ticket status",
+			path:           "/tickets-status",
+			body:           fmt.Sprintf(`{"tickets": [{"ticket_id": %q, "status": "lost", "price": {"amount": "10", "currency": "EUR"}}]}`, uuid.New()),
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedFields: []string{"tickets[0].status"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, "http://localhost:8000"+tc.path, bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Idempotency-Key", uuid.NewString())
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			require.Equal(t, tc.expectedStatus, resp.StatusCode)
+
+			var body struct {
+				Error struct {
+					Message string `json:"message"`
+					Fields  []struct {
+						Field   string `json:"field"`
+						Message string `json:"message"`
+					} `json:"fields"`
+				} `json:"error"`
+			}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			assert.NotEmpty(t, body.Error.Message)
+
+			fields := make([]string, 0, len(body.Error.Fields))
+			for _, f := range body.Error.Fields {
+				assert.NotEmpty(t, f.Message, "field %s", f.Field)
+				fields = append(fields, f.Field)
+			}
+			assert.ElementsMatch(t, tc.expectedFields, fields)
+		})
+	}
+}
+
+func TestValidation_ticket_without_currency_is_accepted(t *testing.T) {
+	env := startApp(t)
+
+	ticket := testTicket(uuid.NewString(), "confirmed")
+	ticket.Price.Currency = ""
+
+	sendTicketsStatus(t, entities.TicketsStatusRequest{Tickets: []entities.Ticket{ticket}})
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		for _, row := range env.spreadsheetClient.SheetRows("tickets-to-print") {
+			if row[0] == ticket.TicketID {
+				assert.Equal(t, "USD", row[3])
+				return
+			}
+		}
+		assert.Fail(t, "ticket not added to print", ticket.TicketID)
+	}, 10*time.Second, 100*time.Millisecond)
+}